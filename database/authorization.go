@@ -0,0 +1,201 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// CreateAuthorizationRequest inserts a pending authorization request
+func (c *Client) CreateAuthorizationRequest(r *models.AuthorizationRequest) error {
+	query := `
+		INSERT INTO authorization_requests (id, client_id, redirect_uri, scope, state, provider, code_challenge, code_challenge_method, nonce, prompt, max_age, device_user_code, created, expires_at, redirect_uri_given)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15);
+	`
+
+	r.Created = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		r.ID,
+		r.ClientID,
+		r.RedirectURI,
+		r.Scope,
+		r.State,
+		r.Provider,
+		r.CodeChallenge,
+		r.CodeChallengeMethod,
+		r.Nonce,
+		r.Prompt,
+		nullableMaxAge(r.MaxAge),
+		r.DeviceUserCode,
+		r.Created,
+		r.ExpiresAt,
+		r.RedirectURIGiven,
+	)
+	return err
+}
+
+// GetAuthorizationRequest selects a pending authorization request from its ID
+func (c *Client) GetAuthorizationRequest(id string) (*models.AuthorizationRequest, error) {
+	query := `
+		SELECT id, client_id, redirect_uri, scope, state, provider, code_challenge, code_challenge_method, nonce, prompt, max_age, device_user_code, user_id, auth_time, sid, created, expires_at, redirect_uri_given
+		FROM authorization_requests
+		WHERE id = $1;
+	`
+	r := models.AuthorizationRequest{}
+	var (
+		maxAge, userID sql.NullInt64
+		authTime       pq.NullTime
+	)
+	err := c.Connection.QueryRow(query, id).Scan(
+		&r.ID,
+		&r.ClientID,
+		&r.RedirectURI,
+		&r.Scope,
+		&r.State,
+		&r.Provider,
+		&r.CodeChallenge,
+		&r.CodeChallengeMethod,
+		&r.Nonce,
+		&r.Prompt,
+		&maxAge,
+		&r.DeviceUserCode,
+		&userID,
+		&authTime,
+		&r.SID,
+		&r.Created,
+		&r.ExpiresAt,
+		&r.RedirectURIGiven,
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.UserID = int(userID.Int64)
+	r.AuthTime = authTime.Time
+	r.MaxAge = -1
+	if maxAge.Valid {
+		r.MaxAge = int(maxAge.Int64)
+	}
+
+	return &r, nil
+}
+
+// SetAuthorizationRequestUser records the session of the user who logged in
+// for a request, until he gives his consent
+func (c *Client) SetAuthorizationRequestUser(id string, session *models.Session) error {
+	res, err := c.Connection.Exec(
+		`UPDATE authorization_requests SET user_id = $2, provider = $3, auth_time = $4, sid = $5 WHERE id = $1;`,
+		id,
+		session.UserID,
+		session.Provider,
+		session.AuthTime,
+		session.SID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(res)
+}
+
+// DeleteAuthorizationRequest removes a request once it has been completed. It
+// returns sql.ErrNoRows if it already was, so a request is only completed once.
+func (c *Client) DeleteAuthorizationRequest(id string) error {
+	res, err := c.Connection.Exec(`DELETE FROM authorization_requests WHERE id = $1;`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(res)
+}
+
+// CreateAuthorizationCode inserts a new authorization code. Only its hash is
+// stored.
+func (c *Client) CreateAuthorizationCode(code *models.AuthorizationCode) error {
+	query := `
+		INSERT INTO authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method, nonce, provider, auth_time, sid, created, expires_at, redirect_uri_given)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+	`
+
+	code.Created = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		models.HashSecret(code.Code),
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		code.Scope,
+		code.CodeChallenge,
+		code.CodeChallengeMethod,
+		code.Nonce,
+		code.Provider,
+		code.AuthTime,
+		code.SID,
+		code.Created,
+		code.ExpiresAt,
+		code.RedirectURIGiven,
+	)
+	return err
+}
+
+// GetAuthorizationCode selects an authorization code, used or not
+func (c *Client) GetAuthorizationCode(code string) (*models.AuthorizationCode, error) {
+	query := `
+		SELECT client_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method, nonce, provider, auth_time, sid, created, expires_at, used, redirect_uri_given, family_id
+		FROM authorization_codes
+		WHERE code_hash = $1;
+	`
+	a := models.AuthorizationCode{Code: code}
+	err := c.Connection.QueryRow(query, models.HashSecret(code)).Scan(
+		&a.ClientID,
+		&a.UserID,
+		&a.RedirectURI,
+		&a.Scope,
+		&a.CodeChallenge,
+		&a.CodeChallengeMethod,
+		&a.Nonce,
+		&a.Provider,
+		&a.AuthTime,
+		&a.SID,
+		&a.Created,
+		&a.ExpiresAt,
+		&a.Used,
+		&a.RedirectURIGiven,
+		&a.FamilyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// UseAuthorizationCode marks a code as used, recording the family of the
+// tokens issued for it. It returns false if it already was, so two concurrent
+// exchanges can't both succeed.
+func (c *Client) UseAuthorizationCode(code, familyID string) (bool, error) {
+	query := `
+		UPDATE authorization_codes
+		SET used = TRUE, family_id = $2
+		WHERE code_hash = $1 AND used = FALSE;
+	`
+	res, err := c.Connection.Exec(query, models.HashSecret(code), familyID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// A request without max_age (-1) stores NULL, 0 being a valid max_age
+func nullableMaxAge(maxAge int) interface{} {
+	if maxAge < 0 {
+		return nil
+	}
+	return maxAge
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/socialement-competents/goauth/models"
+)
+
+const clientColumns = `id, secret_hash, name, redirect_uris, scopes, providers, grant_types, created, access_token_lifetime, refresh_token_lifetime, public, machine_scopes, exchange_audiences, first_party, post_logout_redirect_uris, backchannel_logout_uri, upstream_providers`
+
+// CreateClient registers a new client application
+func (c *Client) CreateClient(client *models.Client) error {
+	query := `
+		INSERT INTO clients (` + clientColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17);
+	`
+
+	client.Created = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		client.ID,
+		client.SecretHash,
+		client.Name,
+		pq.Array(client.RedirectURIs),
+		pq.Array(client.Scopes),
+		pq.Array(client.Providers),
+		pq.Array(client.GrantTypes),
+		client.Created,
+		client.AccessTokenLifetime,
+		client.RefreshTokenLifetime,
+		client.Public,
+		pq.Array(client.MachineScopes),
+		pq.Array(client.ExchangeAudiences),
+		client.FirstParty,
+		pq.Array(client.PostLogoutRedirectURIs),
+		client.BackchannelLogoutURI,
+		pq.Array(client.UpstreamProviders),
+	)
+	return err
+}
+
+// GetClient selects a client application from its ID
+func (c *Client) GetClient(id string) (*models.Client, error) {
+	query := `
+		SELECT ` + clientColumns + `
+		FROM clients
+		WHERE id = $1;
+	`
+	return scanClient(c.Connection.QueryRow(query, id))
+}
+
+// ListClients selects every client application
+func (c *Client) ListClients() ([]*models.Client, error) {
+	query := `
+		SELECT ` + clientColumns + `
+		FROM clients
+		ORDER BY created;
+	`
+	rows, err := c.Connection.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []*models.Client{}
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+// UpdateClient will update a client application from its ID
+func (c *Client) UpdateClient(client *models.Client) error {
+	query := `
+		UPDATE clients
+		SET
+			secret_hash = $2,
+			name = $3,
+			redirect_uris = $4,
+			scopes = $5,
+			providers = $6,
+			grant_types = $7,
+			access_token_lifetime = $8,
+			refresh_token_lifetime = $9,
+			public = $10,
+			machine_scopes = $11,
+			exchange_audiences = $12,
+			first_party = $13,
+			post_logout_redirect_uris = $14,
+			backchannel_logout_uri = $15,
+			upstream_providers = $16
+		WHERE id = $1;
+	`
+
+	res, err := c.Connection.Exec(
+		query,
+		client.ID,
+		client.SecretHash,
+		client.Name,
+		pq.Array(client.RedirectURIs),
+		pq.Array(client.Scopes),
+		pq.Array(client.Providers),
+		pq.Array(client.GrantTypes),
+		client.AccessTokenLifetime,
+		client.RefreshTokenLifetime,
+		client.Public,
+		pq.Array(client.MachineScopes),
+		pq.Array(client.ExchangeAudiences),
+		client.FirstParty,
+		pq.Array(client.PostLogoutRedirectURIs),
+		client.BackchannelLogoutURI,
+		pq.Array(client.UpstreamProviders),
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(res)
+}
+
+// DeleteClient removes a client application, along with its codes and tokens
+func (c *Client) DeleteClient(id string) error {
+	res, err := c.Connection.Exec(`DELETE FROM clients WHERE id = $1;`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(res)
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanClient(row scanner) (*models.Client, error) {
+	client := models.Client{}
+	err := row.Scan(
+		&client.ID,
+		&client.SecretHash,
+		&client.Name,
+		pq.Array(&client.RedirectURIs),
+		pq.Array(&client.Scopes),
+		pq.Array(&client.Providers),
+		pq.Array(&client.GrantTypes),
+		&client.Created,
+		&client.AccessTokenLifetime,
+		&client.RefreshTokenLifetime,
+		&client.Public,
+		pq.Array(&client.MachineScopes),
+		pq.Array(&client.ExchangeAudiences),
+		&client.FirstParty,
+		pq.Array(&client.PostLogoutRedirectURIs),
+		&client.BackchannelLogoutURI,
+		pq.Array(&client.UpstreamProviders),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// expectOneRow returns sql.ErrNoRows when a statement didn't affect any row
+func expectOneRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
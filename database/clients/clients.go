@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+const usage = `usage:
+  clients list
+  clients create -id ID -name NAME -redirect-uris URI[,URI] -scopes SCOPE[,SCOPE] [-providers ...] [-grant-types ...] [-machine-scopes ...] [-exchange-audiences ...] [-public] [-first-party] [-post-logout-redirect-uris ...] [-backchannel-logout-uri URI]
+  clients rotate-secret -id ID
+  clients delete -id ID`
+
+func randomSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func split(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	return strings.Split(list, ",")
+}
+
+func list(client *database.Client) {
+	clients, err := client.ListClients()
+	if err != nil {
+		panic(fmt.Sprintf("listing the clients failed: %v", err))
+	}
+
+	for _, c := range clients {
+		fmt.Printf("%s\t%s\t%v\n", c.ID, c.Name, c.RedirectURIs)
+	}
+}
+
+func create(client *database.Client, args []string) {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	id := flags.String("id", "", "client ID")
+	name := flags.String("name", "", "application name")
+	redirectURIs := flags.String("redirect-uris", "", "comma separated redirection URIs")
+	scopes := flags.String("scopes", "", "comma separated allowed scopes")
+	providers := flags.String("providers", "github,fitbit", "comma separated allowed providers")
+	grantTypes := flags.String("grant-types", "authorization_code,refresh_token", "comma separated allowed grant types")
+	machineScopes := flags.String("machine-scopes", "", "comma separated scopes of the client_credentials grant")
+	exchangeAudiences := flags.String("exchange-audiences", "", "comma separated client IDs the client can exchange tokens for")
+	public := flags.Bool("public", false, "public client (SPA, mobile app), without secret and required to use PKCE")
+	firstParty := flags.Bool("first-party", false, "our own application, users aren't asked for their consent")
+	postLogoutRedirectURIs := flags.String("post-logout-redirect-uris", "", "comma separated URIs users can be sent to after logging out")
+	backchannelLogoutURI := flags.String("backchannel-logout-uri", "", "URI receiving the logout tokens")
+	upstreamProviders := flags.String("upstream-providers", "", "comma separated providers whose user tokens the client can get")
+	flags.Parse(args)
+
+	if *id == "" || *redirectURIs == "" {
+		fmt.Println(usage)
+		return
+	}
+
+	c := &models.Client{
+		ID:           *id,
+		Name:         *name,
+		RedirectURIs: split(*redirectURIs),
+		Scopes:       split(*scopes),
+		Providers:    split(*providers),
+		GrantTypes:   split(*grantTypes),
+		Public:       *public,
+		FirstParty:   *firstParty,
+
+		MachineScopes:     split(*machineScopes),
+		ExchangeAudiences: split(*exchangeAudiences),
+		UpstreamProviders: split(*upstreamProviders),
+
+		PostLogoutRedirectURIs: split(*postLogoutRedirectURIs),
+		BackchannelLogoutURI:   *backchannelLogoutURI,
+	}
+
+	secret := ""
+	if !c.Public {
+		secret = randomSecret()
+		c.SetSecret(secret)
+	}
+
+	if err := client.CreateClient(c); err != nil {
+		panic(fmt.Sprintf("creating the client failed: %v", err))
+	}
+
+	fmt.Println("created client", c.ID)
+	if secret != "" {
+		fmt.Println("secret (it won't be displayed again):", secret)
+	}
+}
+
+func rotateSecret(client *database.Client, args []string) {
+	flags := flag.NewFlagSet("rotate-secret", flag.ExitOnError)
+	id := flags.String("id", "", "client ID")
+	flags.Parse(args)
+
+	c, err := client.GetClient(*id)
+	if err != nil {
+		panic(fmt.Sprintf("getting the client failed: %v", err))
+	}
+
+	secret := randomSecret()
+	c.SetSecret(secret)
+	if err = client.UpdateClient(c); err != nil {
+		panic(fmt.Sprintf("updating the client failed: %v", err))
+	}
+
+	fmt.Println("new secret (it won't be displayed again):", secret)
+}
+
+func remove(client *database.Client, args []string) {
+	flags := flag.NewFlagSet("delete", flag.ExitOnError)
+	id := flags.String("id", "", "client ID")
+	flags.Parse(args)
+
+	if err := client.DeleteClient(*id); err != nil {
+		panic(fmt.Sprintf("deleting the client failed: %v", err))
+	}
+
+	fmt.Println("deleted client", *id)
+}
+
+func main() {
+	client, err := database.NewClient()
+	if err != nil {
+		fmt.Println("connecting to the database failed: ", err)
+		return
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		return
+	}
+
+	switch os.Args[1] {
+	case "list":
+		list(client)
+	case "create":
+		create(client, os.Args[2:])
+	case "rotate-secret":
+		rotateSecret(client, os.Args[2:])
+	case "delete":
+		remove(client, os.Args[2:])
+	default:
+		fmt.Println(usage)
+	}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestClientLifecycle(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("connecting the db failed: %v", err)
+	}
+
+	c := &models.Client{
+		ID:           "test-client",
+		Name:         "Test",
+		RedirectURIs: []string{"https://example.com/callback"},
+		Scopes:       []string{"openid"},
+		Providers:    []string{models.GithubProvider},
+		GrantTypes:   []string{models.AuthorizationCodeGrant},
+	}
+	c.SetSecret("secret")
+
+	if err = client.CreateClient(c); err != nil {
+		t.Fatalf("creating the client failed: %v", err)
+	}
+	defer client.DeleteClient(c.ID)
+
+	c.Name = "Renamed"
+	if err = client.UpdateClient(c); err != nil {
+		t.Errorf("updating the client failed: %v", err)
+	}
+
+	got, err := client.GetClient(c.ID)
+	if err != nil {
+		t.Fatalf("getting the client failed: %v", err)
+	}
+	if got.Name != "Renamed" || !got.CheckSecret("secret") || !got.HasRedirectURI("https://example.com/callback") {
+		t.Errorf("unexpected client %+v", got)
+	}
+
+	if err = client.DeleteClient(c.ID); err != nil {
+		t.Errorf("deleting the client failed: %v", err)
+	}
+	if _, err = client.GetClient(c.ID); err == nil {
+		t.Error("the client should be deleted")
+	}
+}
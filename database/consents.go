@@ -0,0 +1,119 @@
+package database
+
+import (
+	"time"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// GetConsent selects the consent a user gave a client
+func (c *Client) GetConsent(userID int, clientID string) (*models.Consent, error) {
+	query := `
+		SELECT user_id, client_id, scope, created, updated
+		FROM consents
+		WHERE user_id = $1 AND client_id = $2;
+	`
+	consent := models.Consent{}
+	err := c.Connection.QueryRow(query, userID, clientID).Scan(
+		&consent.UserID,
+		&consent.ClientID,
+		&consent.Scope,
+		&consent.Created,
+		&consent.Updated,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consent, nil
+}
+
+// ListConsents selects every consent a user gave
+func (c *Client) ListConsents(userID int) ([]*models.Consent, error) {
+	query := `
+		SELECT user_id, client_id, scope, created, updated
+		FROM consents
+		WHERE user_id = $1
+		ORDER BY created;
+	`
+	rows, err := c.Connection.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []*models.Consent{}
+	for rows.Next() {
+		consent := models.Consent{}
+		err = rows.Scan(
+			&consent.UserID,
+			&consent.ClientID,
+			&consent.Scope,
+			&consent.Created,
+			&consent.Updated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, &consent)
+	}
+
+	return consents, rows.Err()
+}
+
+// SaveConsent inserts a consent, or replaces the scope of the existing one
+func (c *Client) SaveConsent(consent *models.Consent) error {
+	query := `
+		INSERT INTO consents (user_id, client_id, scope, created, updated)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (user_id, client_id)
+		DO UPDATE SET scope = $3, updated = $4;
+	`
+
+	consent.Updated = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		consent.UserID,
+		consent.ClientID,
+		consent.Scope,
+		consent.Updated,
+	)
+	return err
+}
+
+// WithdrawConsent removes the consent a user gave a client, and revokes every
+// token the client holds for him, along with the tokens exchanged from them
+func (c *Client) WithdrawConsent(userID int, clientID string) error {
+	tx, err := c.Connection.Begin()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`DELETE FROM consents WHERE user_id = $1 AND client_id = $2;`, userID, clientID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = expectOneRow(res); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	revokeAccessTokens := `
+		UPDATE access_tokens
+		SET revoked = TRUE
+		WHERE (user_id = $1 AND client_id = $2)
+		OR family_id IN (SELECT family_id FROM refresh_tokens WHERE user_id = $1 AND client_id = $2);
+	`
+	if _, err = tx.Exec(revokeAccessTokens, userID, clientID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(`UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND client_id = $2;`, userID, clientID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
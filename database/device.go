@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// CreateDeviceAuthorization inserts a new device authorization. Only the hash
+// of the device code is stored.
+func (c *Client) CreateDeviceAuthorization(d *models.DeviceAuthorization) error {
+	query := `
+		INSERT INTO device_authorizations (device_code_hash, user_code, client_id, scope, status, polling_interval, created, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	d.Created = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		models.HashSecret(d.DeviceCode),
+		d.UserCode,
+		d.ClientID,
+		d.Scope,
+		d.Status,
+		d.Interval,
+		d.Created,
+		d.ExpiresAt,
+	)
+	return err
+}
+
+// GetDeviceAuthorization selects a device authorization from its device code
+func (c *Client) GetDeviceAuthorization(deviceCode string) (*models.DeviceAuthorization, error) {
+	return c.getDeviceAuthorization("device_code_hash", models.HashSecret(deviceCode))
+}
+
+// GetDeviceAuthorizationByUserCode selects a device authorization from the
+// code the user entered
+func (c *Client) GetDeviceAuthorizationByUserCode(userCode string) (*models.DeviceAuthorization, error) {
+	return c.getDeviceAuthorization("user_code", userCode)
+}
+
+// column is never user input
+func (c *Client) getDeviceAuthorization(column, value string) (*models.DeviceAuthorization, error) {
+	query := `
+		SELECT user_code, client_id, scope, status, user_id, provider, auth_time, polling_interval, last_polled, created, expires_at
+		FROM device_authorizations
+		WHERE ` + column + ` = $1;
+	`
+	d := models.DeviceAuthorization{}
+	var (
+		userID               sql.NullInt64
+		authTime, lastPolled pq.NullTime
+	)
+	err := c.Connection.QueryRow(query, value).Scan(
+		&d.UserCode,
+		&d.ClientID,
+		&d.Scope,
+		&d.Status,
+		&userID,
+		&d.Provider,
+		&authTime,
+		&d.Interval,
+		&lastPolled,
+		&d.Created,
+		&d.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	d.UserID = int(userID.Int64)
+	d.AuthTime = authTime.Time
+	d.LastPolled = lastPolled.Time
+	return &d, nil
+}
+
+// ApproveDeviceAuthorization records the user who approved a pending device
+// authorization
+func (c *Client) ApproveDeviceAuthorization(userCode string, userID int, provider string, authTime time.Time) error {
+	query := `
+		UPDATE device_authorizations
+		SET status = $2, user_id = $3, provider = $4, auth_time = $5
+		WHERE user_code = $1 AND status = $6;
+	`
+	res, err := c.Connection.Exec(
+		query,
+		userCode,
+		models.DeviceApproved,
+		userID,
+		provider,
+		authTime,
+		models.DevicePending,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(res)
+}
+
+// DenyDeviceAuthorization records that the user refused a pending device
+// authorization
+func (c *Client) DenyDeviceAuthorization(userCode string) error {
+	res, err := c.Connection.Exec(
+		`UPDATE device_authorizations SET status = $2 WHERE user_code = $1 AND status = $3;`,
+		userCode,
+		models.DeviceDenied,
+		models.DevicePending,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(res)
+}
+
+// UpdateDevicePolling records when the device last polled, and its interval
+func (c *Client) UpdateDevicePolling(deviceCode string, lastPolled time.Time, interval int) error {
+	_, err := c.Connection.Exec(
+		`UPDATE device_authorizations SET last_polled = $2, polling_interval = $3 WHERE device_code_hash = $1;`,
+		models.HashSecret(deviceCode),
+		lastPolled,
+		interval,
+	)
+	return err
+}
+
+// UseDeviceAuthorization marks an approved authorization as used. It returns
+// false if it already was, so the tokens can only be issued once.
+func (c *Client) UseDeviceAuthorization(deviceCode string) (bool, error) {
+	res, err := c.Connection.Exec(
+		`UPDATE device_authorizations SET status = $2 WHERE device_code_hash = $1 AND status = $3;`,
+		models.HashSecret(deviceCode),
+		models.DeviceUsed,
+		models.DeviceApproved,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"time"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// CreateIdentity links a provider account to a user
+func (c *Client) CreateIdentity(i *models.Identity) error {
+	query := `
+		INSERT INTO identities (user_id, provider, subject, profile, created, updated)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING id;
+	`
+
+	i.Created = time.Now()
+	i.Updated = i.Created
+
+	return c.Connection.QueryRow(
+		query,
+		i.UserID,
+		i.Provider,
+		i.Subject,
+		profileJSON(i.Profile),
+		i.Created,
+	).Scan(&i.ID)
+}
+
+// GetIdentity selects the identity of a provider account
+func (c *Client) GetIdentity(provider, subject string) (*models.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, profile, created, updated
+		FROM identities
+		WHERE provider = $1 AND subject = $2;
+	`
+	i := models.Identity{}
+	err := c.Connection.QueryRow(query, provider, subject).Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.Profile,
+		&i.Created,
+		&i.Updated,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+// ListIdentities selects every identity of a user
+func (c *Client) ListIdentities(userID int) ([]*models.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, profile, created, updated
+		FROM identities
+		WHERE user_id = $1
+		ORDER BY created;
+	`
+	return c.listIdentities(query, userID)
+}
+
+func (c *Client) listIdentities(query string, args ...interface{}) ([]*models.Identity, error) {
+	rows, err := c.Connection.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []*models.Identity{}
+	for rows.Next() {
+		i := models.Identity{}
+		err = rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Provider,
+			&i.Subject,
+			&i.Profile,
+			&i.Created,
+			&i.Updated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, &i)
+	}
+
+	return identities, rows.Err()
+}
+
+// ListIdentitiesBySubjectPrefix selects the identities of a provider whose
+// subject starts with prefix
+func (c *Client) ListIdentitiesBySubjectPrefix(provider, prefix string) ([]*models.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, profile, created, updated
+		FROM identities
+		WHERE provider = $1 AND LEFT(subject, LENGTH($2)) = $2
+		ORDER BY created;
+	`
+	return c.listIdentities(query, provider, prefix)
+}
+
+// UpdateIdentitySubject changes the identifier of the provider account
+func (c *Client) UpdateIdentitySubject(i *models.Identity) error {
+	query := `
+		UPDATE identities
+		SET subject = $2, updated = $3
+		WHERE id = $1;
+	`
+
+	i.Updated = time.Now()
+
+	res, err := c.Connection.Exec(query, i.ID, i.Subject, i.Updated)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// UpdateIdentityProfile stores the profile last fetched from the provider
+func (c *Client) UpdateIdentityProfile(i *models.Identity) error {
+	query := `
+		UPDATE identities
+		SET profile = $2, updated = $3
+		WHERE id = $1;
+	`
+
+	i.Updated = time.Now()
+
+	res, err := c.Connection.Exec(query, i.ID, profileJSON(i.Profile), i.Updated)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// DeleteIdentity unlinks the account of a provider from a user
+func (c *Client) DeleteIdentity(userID int, provider string) error {
+	res, err := c.Connection.Exec(`DELETE FROM identities WHERE user_id = $1 AND provider = $2;`, userID, provider)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// CreateLinkRequest inserts a new link request
+func (c *Client) CreateLinkRequest(r *models.LinkRequest) error {
+	query := `
+		INSERT INTO link_requests (id, user_id, sid, provider, created, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6);
+	`
+
+	r.Created = time.Now()
+
+	_, err := c.Connection.Exec(query, r.ID, r.UserID, r.SID, r.Provider, r.Created, r.ExpiresAt)
+	return err
+}
+
+// GetLinkRequest selects a link request from its ID
+func (c *Client) GetLinkRequest(id string) (*models.LinkRequest, error) {
+	query := `
+		SELECT id, user_id, sid, provider, created, expires_at
+		FROM link_requests
+		WHERE id = $1;
+	`
+	r := models.LinkRequest{}
+	err := c.Connection.QueryRow(query, id).Scan(
+		&r.ID,
+		&r.UserID,
+		&r.SID,
+		&r.Provider,
+		&r.Created,
+		&r.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// DeleteLinkRequest removes a link request once completed. It returns
+// sql.ErrNoRows when it was already completed.
+func (c *Client) DeleteLinkRequest(id string) error {
+	res, err := c.Connection.Exec(`DELETE FROM link_requests WHERE id = $1;`, id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+// profileJSON returns the profile to store, an empty object when unknown
+func profileJSON(profile []byte) string {
+	if len(profile) == 0 {
+		return "{}"
+	}
+	return string(profile)
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// CreateSigningKey inserts a new signing key
+func (c *Client) CreateSigningKey(k *models.SigningKey) error {
+	query := `
+		INSERT INTO signing_keys (id, algorithm, status, public_key, private_key, created)
+		VALUES ($1, $2, $3, $4, $5, $6);
+	`
+
+	k.Created = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		k.ID,
+		k.Algorithm,
+		k.Status,
+		k.PublicKey,
+		k.PrivateKey,
+		k.Created,
+	)
+	return err
+}
+
+// GetSigningKeys selects the signing keys having one of the statuses, the
+// newest first
+func (c *Client) GetSigningKeys(statuses ...string) ([]*models.SigningKey, error) {
+	query := `
+		SELECT id, algorithm, status, public_key, private_key, created, activated_at, retire_at
+		FROM signing_keys
+		WHERE status = ANY($1)
+		ORDER BY created DESC;
+	`
+	rows, err := c.Connection.Query(query, pq.Array(statuses))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*models.SigningKey{}
+	for rows.Next() {
+		k := models.SigningKey{}
+		var activatedAt, retireAt pq.NullTime
+		err = rows.Scan(
+			&k.ID,
+			&k.Algorithm,
+			&k.Status,
+			&k.PublicKey,
+			&k.PrivateKey,
+			&k.Created,
+			&activatedAt,
+			&retireAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		k.ActivatedAt = activatedAt.Time
+		k.RetireAt = retireAt.Time
+		keys = append(keys, &k)
+	}
+
+	return keys, rows.Err()
+}
+
+// ActivateSigningKey makes a key the active one. The previously active keys
+// keep being published until retireAt.
+func (c *Client) ActivateSigningKey(id string, retireAt time.Time) error {
+	tx, err := c.Connection.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE signing_keys SET status = $1, retire_at = $2 WHERE status = $3;`,
+		models.KeyRetiring,
+		retireAt,
+		models.KeyActive,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec(
+		`UPDATE signing_keys SET status = $1, activated_at = $2 WHERE id = $3;`,
+		models.KeyActive,
+		time.Now(),
+		id,
+	)
+	if err == nil {
+		err = expectOneRow(res)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RetireSigningKeys stops publishing the retiring keys whose tokens expired
+func (c *Client) RetireSigningKeys(now time.Time) (int64, error) {
+	res, err := c.Connection.Exec(
+		`UPDATE signing_keys SET status = $1 WHERE status = $2 AND retire_at < $3;`,
+		models.KeyRetired,
+		models.KeyRetiring,
+		now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"time"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// CreateLogoutDelivery queues a back-channel logout notification
+func (c *Client) CreateLogoutDelivery(d *models.LogoutDelivery) error {
+	query := `
+		INSERT INTO logout_deliveries (client_id, user_id, sid, attempts, next_attempt, created)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+
+	d.Created = time.Now()
+
+	return c.Connection.QueryRow(
+		query,
+		d.ClientID,
+		d.UserID,
+		d.SID,
+		d.Attempts,
+		d.NextAttempt,
+		d.Created,
+	).Scan(&d.ID)
+}
+
+// ListDueLogoutDeliveries selects the notifications to send again
+func (c *Client) ListDueLogoutDeliveries(now time.Time) ([]*models.LogoutDelivery, error) {
+	query := `
+		SELECT id, client_id, user_id, sid, attempts, next_attempt, created
+		FROM logout_deliveries
+		WHERE next_attempt <= $1
+		ORDER BY next_attempt;
+	`
+	rows, err := c.Connection.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.LogoutDelivery{}
+	for rows.Next() {
+		d := models.LogoutDelivery{}
+		err = rows.Scan(
+			&d.ID,
+			&d.ClientID,
+			&d.UserID,
+			&d.SID,
+			&d.Attempts,
+			&d.NextAttempt,
+			&d.Created,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// UpdateLogoutDelivery records a failed attempt
+func (c *Client) UpdateLogoutDelivery(d *models.LogoutDelivery) error {
+	_, err := c.Connection.Exec(
+		`UPDATE logout_deliveries SET attempts = $2, next_attempt = $3 WHERE id = $1;`,
+		d.ID,
+		d.Attempts,
+		d.NextAttempt,
+	)
+	return err
+}
+
+// DeleteLogoutDelivery removes a notification once received, or given up
+func (c *Client) DeleteLogoutDelivery(id int) error {
+	_, err := c.Connection.Exec(`DELETE FROM logout_deliveries WHERE id = $1;`, id)
+	return err
+}
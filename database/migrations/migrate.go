@@ -5,6 +5,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/socialement-competents/goauth/database"
 )
@@ -22,12 +25,30 @@ func runFile(filename string, client *database.Client) {
 	}
 }
 
+// migrationNumber returns the number a migration file name starts with
+func migrationNumber(name string) int {
+	digits := strings.IndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		digits = len(name)
+	}
+	n, err := strconv.Atoi(name[:digits])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
 func runDir(dirname string, client *database.Client) {
 	files, err := ioutil.ReadDir(dirname)
 	if err != nil {
 		panic(fmt.Sprintf("reading the %s directory failed: %v", dirname, err))
 	}
 
+	// run the migrations in numeric order, 10Something comes after 9Something
+	sort.SliceStable(files, func(i, j int) bool {
+		return migrationNumber(files[i].Name()) < migrationNumber(files[j].Name())
+	})
+
 	for _, f := range files {
 		filepath := filepath.Join(dirname, f.Name())
 		runFile(filepath, client)
@@ -0,0 +1,10 @@
+ALTER TABLE Clients
+ADD COLUMN public BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE Authorization_Requests
+ADD COLUMN code_challenge VARCHAR (128) NOT NULL DEFAULT '',
+ADD COLUMN code_challenge_method VARCHAR (10) NOT NULL DEFAULT '';
+
+ALTER TABLE Authorization_Codes
+ADD COLUMN code_challenge VARCHAR (128) NOT NULL DEFAULT '',
+ADD COLUMN code_challenge_method VARCHAR (10) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+ALTER TABLE Authorization_Requests
+ADD COLUMN nonce VARCHAR (2000) NOT NULL DEFAULT '';
+
+ALTER TABLE Authorization_Codes
+ADD COLUMN nonce VARCHAR (2000) NOT NULL DEFAULT '',
+ADD COLUMN provider VARCHAR (255) NOT NULL DEFAULT '',
+ADD COLUMN auth_time TIMESTAMP NOT NULL DEFAULT NOW();
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS Signing_Keys (
+    id VARCHAR (255) PRIMARY KEY NOT NULL,
+    algorithm VARCHAR (10) NOT NULL,
+    status VARCHAR (20) NOT NULL,
+    public_key TEXT NOT NULL,
+    private_key TEXT NOT NULL,
+    created TIMESTAMP NOT NULL,
+    activated_at TIMESTAMP,
+    retire_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS signing_keys_status ON Signing_Keys (status);
@@ -0,0 +1,5 @@
+ALTER TABLE Clients
+ADD COLUMN machine_scopes TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE Access_Tokens
+ALTER COLUMN user_id DROP NOT NULL;
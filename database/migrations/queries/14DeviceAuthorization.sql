@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS Device_Authorizations (
+    device_code_hash VARCHAR (64) PRIMARY KEY NOT NULL,
+    user_code VARCHAR (20) UNIQUE NOT NULL,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    scope TEXT NOT NULL,
+    status VARCHAR (20) NOT NULL,
+    user_id INTEGER REFERENCES Users (id) ON DELETE CASCADE,
+    provider VARCHAR (255) NOT NULL DEFAULT '',
+    auth_time TIMESTAMP,
+    polling_interval INTEGER NOT NULL,
+    last_polled TIMESTAMP,
+    created TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE Authorization_Requests
+ADD COLUMN device_user_code VARCHAR (20) NOT NULL DEFAULT '';
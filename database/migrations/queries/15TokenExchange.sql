@@ -0,0 +1,6 @@
+ALTER TABLE Clients
+ADD COLUMN exchange_audiences TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE Access_Tokens
+ADD COLUMN audience VARCHAR (255) NOT NULL DEFAULT '',
+ADD COLUMN act JSONB;
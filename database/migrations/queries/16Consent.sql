@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS Consents (
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    scope TEXT NOT NULL,
+    created TIMESTAMP,
+    updated TIMESTAMP,
+    PRIMARY KEY (user_id, client_id)
+);
+
+ALTER TABLE Clients
+ADD COLUMN first_party BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE Authorization_Requests
+ADD COLUMN user_id INTEGER REFERENCES Users (id) ON DELETE CASCADE,
+ADD COLUMN auth_time TIMESTAMP;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS Sessions (
+    id_hash VARCHAR (64) PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    provider VARCHAR (255) NOT NULL,
+    auth_time TIMESTAMP NOT NULL,
+    created TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE Authorization_Requests
+ADD COLUMN prompt VARCHAR (20) NOT NULL DEFAULT '',
+ADD COLUMN max_age INTEGER;
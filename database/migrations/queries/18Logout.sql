@@ -0,0 +1,32 @@
+ALTER TABLE Clients
+ADD COLUMN post_logout_redirect_uris TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN backchannel_logout_uri VARCHAR (2000) NOT NULL DEFAULT '';
+
+-- the default only fills the sessions started before this migration
+ALTER TABLE Sessions
+ADD COLUMN sid VARCHAR (64) UNIQUE NOT NULL DEFAULT md5(random()::text);
+
+ALTER TABLE Sessions
+ALTER COLUMN sid DROP DEFAULT;
+
+CREATE TABLE IF NOT EXISTS Session_Clients (
+    sid VARCHAR (64) NOT NULL REFERENCES Sessions (sid) ON DELETE CASCADE,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    PRIMARY KEY (sid, client_id)
+);
+
+ALTER TABLE Authorization_Requests
+ADD COLUMN sid VARCHAR (64) NOT NULL DEFAULT '';
+
+ALTER TABLE Authorization_Codes
+ADD COLUMN sid VARCHAR (64) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS Logout_Deliveries (
+    id SERIAL PRIMARY KEY,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    sid VARCHAR (64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt TIMESTAMP NOT NULL,
+    created TIMESTAMP
+);
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS Identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    provider VARCHAR (255) NOT NULL,
+    subject VARCHAR (255) NOT NULL,
+    profile JSONB NOT NULL DEFAULT '{}',
+    created TIMESTAMP,
+    updated TIMESTAMP,
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+-- every existing user gets the identity he registered with
+INSERT INTO Identities (user_id, provider, subject, profile, created, updated)
+SELECT id, 'github', login, json_build_object(
+    'login', login,
+    'name', name,
+    'email', email,
+    'avatar_url', image,
+    'bio', bio,
+    'blog', blog,
+    'location', location
+), created, last_login
+FROM Users
+WHERE login IS NOT NULL AND login <> ''
+ON CONFLICT DO NOTHING;
+
+INSERT INTO Identities (user_id, provider, subject, profile, created, updated)
+SELECT id, 'fitbit', fitbit_id, json_build_object(
+    'encodedId', fitbit_id,
+    'fullName', fitbit_fullname,
+    'avatar150', fitbit_avatar150,
+    'age', fitbit_age
+), created, last_login
+FROM Users
+WHERE fitbit_id IS NOT NULL AND fitbit_id <> ''
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS Link_Requests (
+    id VARCHAR (255) PRIMARY KEY NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    provider VARCHAR (255) NOT NULL,
+    created TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- users are identified by their identities now, and an account without
+-- GitHub has no email
+ALTER TABLE Users
+DROP CONSTRAINT IF EXISTS users_email_key;
@@ -0,0 +1,11 @@
+ALTER TABLE Users
+ADD COLUMN github_id BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN github_node_id VARCHAR (255) NOT NULL DEFAULT '';
+
+-- GitHub identities were keyed by login, which can be renamed and taken by
+-- someone else. They are keyed by the numeric GitHub ID on the next login of
+-- their owner, or once the backfill (go run providers/backfill/backfill.go)
+-- resolved it for those who don't log in again.
+UPDATE Identities
+SET subject = 'login:' || subject
+WHERE provider = 'github' AND subject NOT LIKE 'login:%';
@@ -0,0 +1,2 @@
+ALTER TABLE Users
+ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
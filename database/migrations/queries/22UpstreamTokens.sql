@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS Upstream_Tokens (
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    provider VARCHAR (255) NOT NULL,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL DEFAULT '',
+    token_type VARCHAR (255) NOT NULL DEFAULT '',
+    scope TEXT NOT NULL DEFAULT '',
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+    PRIMARY KEY (user_id, provider)
+);
+
+CREATE INDEX IF NOT EXISTS upstream_tokens_expires_at ON Upstream_Tokens (expires_at);
+
+ALTER TABLE Clients
+ADD COLUMN upstream_providers TEXT[] NOT NULL DEFAULT '{}';
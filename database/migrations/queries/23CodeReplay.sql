@@ -0,0 +1,10 @@
+-- redirect_uri only has to be sent with the code when it was sent to
+-- /authorize (RFC 6749 section 4.1.3)
+ALTER TABLE Authorization_Requests
+ADD COLUMN redirect_uri_given BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- the family of the tokens issued for a code, revoked when the code is
+-- replayed (RFC 6749 section 4.1.2)
+ALTER TABLE Authorization_Codes
+ADD COLUMN redirect_uri_given BOOLEAN NOT NULL DEFAULT TRUE,
+ADD COLUMN family_id VARCHAR (64) NOT NULL DEFAULT '';
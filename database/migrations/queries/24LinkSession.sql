@@ -0,0 +1,3 @@
+-- a link is only completed in the browser of the session that started it
+ALTER TABLE Link_Requests
+ADD COLUMN sid VARCHAR (64) NOT NULL DEFAULT '';
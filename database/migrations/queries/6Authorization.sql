@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS Clients (
+    id VARCHAR (255) PRIMARY KEY NOT NULL,
+    redirect_uris TEXT[] NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS Authorization_Requests (
+    id VARCHAR (255) PRIMARY KEY NOT NULL,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    redirect_uri VARCHAR (2000) NOT NULL,
+    scope TEXT NOT NULL,
+    state VARCHAR (2000),
+    provider VARCHAR (255) NOT NULL,
+    created TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS Authorization_Codes (
+    code_hash VARCHAR (64) PRIMARY KEY NOT NULL,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    redirect_uri VARCHAR (2000) NOT NULL,
+    scope TEXT NOT NULL,
+    created TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used BOOLEAN NOT NULL DEFAULT FALSE
+);
@@ -0,0 +1,12 @@
+ALTER TABLE Clients
+ADD COLUMN secret_hash VARCHAR (64) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS Access_Tokens (
+    token_hash VARCHAR (64) PRIMARY KEY NOT NULL,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    scope TEXT NOT NULL,
+    created TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE
+);
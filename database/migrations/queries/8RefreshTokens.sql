@@ -0,0 +1,21 @@
+ALTER TABLE Clients
+ADD COLUMN access_token_lifetime INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN refresh_token_lifetime INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE Access_Tokens
+ADD COLUMN family_id VARCHAR (255) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS Refresh_Tokens (
+    token_hash VARCHAR (64) PRIMARY KEY NOT NULL,
+    family_id VARCHAR (255) NOT NULL,
+    client_id VARCHAR (255) NOT NULL REFERENCES Clients (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES Users (id) ON DELETE CASCADE,
+    scope TEXT NOT NULL,
+    created TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used BOOLEAN NOT NULL DEFAULT FALSE,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family ON Refresh_Tokens (family_id);
+CREATE INDEX IF NOT EXISTS access_tokens_family ON Access_Tokens (family_id);
@@ -0,0 +1,4 @@
+ALTER TABLE Clients
+ADD COLUMN name VARCHAR (255) NOT NULL DEFAULT '',
+ADD COLUMN providers TEXT[] NOT NULL DEFAULT '{github,fitbit}',
+ADD COLUMN grant_types TEXT[] NOT NULL DEFAULT '{authorization_code,refresh_token}';
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"time"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// CreateSession inserts a new session. Only the hash of its ID is stored.
+func (c *Client) CreateSession(s *models.Session) error {
+	query := `
+		INSERT INTO sessions (id_hash, sid, user_id, provider, auth_time, created, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	s.Created = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		models.HashSecret(s.ID),
+		s.SID,
+		s.UserID,
+		s.Provider,
+		s.AuthTime,
+		s.Created,
+		s.ExpiresAt,
+	)
+	return err
+}
+
+// GetSession selects a session from its secret ID, expired or not
+func (c *Client) GetSession(id string) (*models.Session, error) {
+	s, err := c.getSession("id_hash", models.HashSecret(id))
+	if err != nil {
+		return nil, err
+	}
+
+	s.ID = id
+	return s, nil
+}
+
+// GetSessionBySID selects a session from its public identifier
+func (c *Client) GetSessionBySID(sid string) (*models.Session, error) {
+	return c.getSession("sid", sid)
+}
+
+// column is never user input
+func (c *Client) getSession(column, value string) (*models.Session, error) {
+	query := `
+		SELECT sid, user_id, provider, auth_time, created, expires_at
+		FROM sessions
+		WHERE ` + column + ` = $1;
+	`
+	s := models.Session{}
+	err := c.Connection.QueryRow(query, value).Scan(
+		&s.SID,
+		&s.UserID,
+		&s.Provider,
+		&s.AuthTime,
+		&s.Created,
+		&s.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// DeleteSession ends a session, and returns the IDs of the clients that took
+// part in it
+func (c *Client) DeleteSession(sid string) ([]string, error) {
+	tx, err := c.Connection.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`SELECT client_id FROM session_clients WHERE sid = $1;`, sid)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	clientIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		clientIDs = append(clientIDs, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// session_clients rows are deleted by the cascade
+	res, err := tx.Exec(`DELETE FROM sessions WHERE sid = $1;`, sid)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = expectOneRow(res); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return clientIDs, tx.Commit()
+}
+
+// AddSessionClient records that a client got an authorization during the
+// session
+func (c *Client) AddSessionClient(sid, clientID string) error {
+	_, err := c.Connection.Exec(
+		`INSERT INTO session_clients (sid, client_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`,
+		sid,
+		clientID,
+	)
+	return err
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// CreateAccessToken inserts a new access token. Only its hash is stored.
+func (c *Client) CreateAccessToken(t *models.AccessToken) error {
+	query := `
+		INSERT INTO access_tokens (token_hash, client_id, user_id, scope, family_id, created, expires_at, audience, act)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+
+	t.Created = time.Now()
+
+	var act []byte
+	if t.Actor != nil {
+		var err error
+		if act, err = json.Marshal(t.Actor); err != nil {
+			return err
+		}
+	}
+
+	_, err := c.Connection.Exec(
+		query,
+		models.HashSecret(t.Token),
+		t.ClientID,
+		nullableID(t.UserID),
+		t.Scope,
+		t.FamilyID,
+		t.Created,
+		t.ExpiresAt,
+		t.Audience,
+		act,
+	)
+	return err
+}
+
+// GetAccessToken selects an access token, active or not
+func (c *Client) GetAccessToken(token string) (*models.AccessToken, error) {
+	query := `
+		SELECT client_id, user_id, scope, family_id, created, expires_at, revoked, audience, act
+		FROM access_tokens
+		WHERE token_hash = $1;
+	`
+	t := models.AccessToken{Token: token}
+	var (
+		userID sql.NullInt64
+		act    []byte
+	)
+	err := c.Connection.QueryRow(query, models.HashSecret(token)).Scan(
+		&t.ClientID,
+		&userID,
+		&t.Scope,
+		&t.FamilyID,
+		&t.Created,
+		&t.ExpiresAt,
+		&t.Revoked,
+		&t.Audience,
+		&act,
+	)
+	if err != nil {
+		return nil, err
+	}
+	t.UserID = int(userID.Int64)
+
+	if act != nil {
+		t.Actor = &models.Actor{}
+		if err = json.Unmarshal(act, t.Actor); err != nil {
+			return nil, err
+		}
+	}
+
+	return &t, nil
+}
+
+// CreateRefreshToken inserts a new refresh token. Only its hash is stored.
+func (c *Client) CreateRefreshToken(t *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (token_hash, family_id, client_id, user_id, scope, created, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	t.Created = time.Now()
+
+	_, err := c.Connection.Exec(
+		query,
+		models.HashSecret(t.Token),
+		t.FamilyID,
+		t.ClientID,
+		t.UserID,
+		t.Scope,
+		t.Created,
+		t.ExpiresAt,
+	)
+	return err
+}
+
+// GetRefreshToken selects a refresh token, used or not
+func (c *Client) GetRefreshToken(token string) (*models.RefreshToken, error) {
+	query := `
+		SELECT family_id, client_id, user_id, scope, created, expires_at, used, revoked
+		FROM refresh_tokens
+		WHERE token_hash = $1;
+	`
+	t := models.RefreshToken{Token: token}
+	err := c.Connection.QueryRow(query, models.HashSecret(token)).Scan(
+		&t.FamilyID,
+		&t.ClientID,
+		&t.UserID,
+		&t.Scope,
+		&t.Created,
+		&t.ExpiresAt,
+		&t.Used,
+		&t.Revoked,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// UseRefreshToken marks a refresh token as rotated. It returns false if it
+// already was, so a token can't be rotated twice concurrently.
+func (c *Client) UseRefreshToken(token string) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET used = TRUE
+		WHERE token_hash = $1 AND used = FALSE AND revoked = FALSE;
+	`
+	res, err := c.Connection.Exec(query, models.HashSecret(token))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// RevokeTokenFamily revokes every access and refresh token issued from the
+// same authorization
+func (c *Client) RevokeTokenFamily(familyID string) error {
+	if familyID == "" {
+		return errors.New("a token family ID is required")
+	}
+
+	tx, err := c.Connection.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE refresh_tokens SET revoked = TRUE WHERE family_id = $1;`, familyID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(`UPDATE access_tokens SET revoked = TRUE WHERE family_id = $1;`, familyID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeAccessToken revokes a single access token
+func (c *Client) RevokeAccessToken(token string) error {
+	_, err := c.Connection.Exec(
+		`UPDATE access_tokens SET revoked = TRUE WHERE token_hash = $1;`,
+		models.HashSecret(token),
+	)
+	return err
+}
+
+// Tokens issued to a client on its own behalf have no user: 0 is stored NULL
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/socialement-competents/goauth/models"
+)
+
+const upstreamTokenColumns = `user_id, provider, access_token, refresh_token, token_type, scope, created, updated, expires_at`
+
+// SaveUpstreamToken inserts the tokens a provider issued for a user, or
+// replaces the previous ones. The tokens must already be encrypted.
+func (c *Client) SaveUpstreamToken(t *models.UpstreamToken) error {
+	return saveUpstreamToken(c.Connection, t)
+}
+
+// execer : the connection or a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveUpstreamToken(db execer, t *models.UpstreamToken) error {
+	query := `
+		INSERT INTO upstream_tokens (` + upstreamTokenColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $8)
+		ON CONFLICT (user_id, provider)
+		DO UPDATE SET access_token = $3, refresh_token = $4, token_type = $5, scope = $6, updated = $7, expires_at = $8;
+	`
+
+	t.Updated = time.Now()
+	if t.Created.IsZero() {
+		t.Created = t.Updated
+	}
+
+	_, err := db.Exec(
+		query,
+		t.UserID,
+		t.Provider,
+		t.AccessToken,
+		t.RefreshToken,
+		t.TokenType,
+		t.Scope,
+		t.Updated,
+		pq.NullTime{Time: t.ExpiresAt, Valid: !t.ExpiresAt.IsZero()},
+	)
+	return err
+}
+
+// GetUpstreamToken selects the tokens a provider issued for a user
+func (c *Client) GetUpstreamToken(userID int, provider string) (*models.UpstreamToken, error) {
+	query := `
+		SELECT ` + upstreamTokenColumns + `
+		FROM upstream_tokens
+		WHERE user_id = $1 AND provider = $2;
+	`
+	return scanUpstreamToken(c.Connection.QueryRow(query, userID, provider))
+}
+
+// RefreshUpstreamToken locks the tokens a provider issued for a user while
+// refresh gets new ones, so that concurrent refreshes don't redeem the same
+// single-use refresh token. refresh is given the stored tokens and returns the
+// ones to store, or nil to keep them; nothing is stored when it fails. It
+// returns the tokens stored in the end.
+func (c *Client) RefreshUpstreamToken(userID int, provider string, refresh func(*models.UpstreamToken) (*models.UpstreamToken, error)) (*models.UpstreamToken, error) {
+	query := `
+		SELECT ` + upstreamTokenColumns + `
+		FROM upstream_tokens
+		WHERE user_id = $1 AND provider = $2
+		FOR UPDATE;
+	`
+
+	tx, err := c.Connection.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := scanUpstreamToken(tx.QueryRow(query, userID, provider))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	refreshed, err := refresh(stored)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if refreshed == nil {
+		return stored, tx.Commit()
+	}
+
+	if err = saveUpstreamToken(tx, refreshed); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return refreshed, tx.Commit()
+}
+
+// DeleteRejectedUpstreamToken forgets the tokens a provider issued for a user
+// once the provider rejected their refresh token, unless they were replaced
+// since
+func (c *Client) DeleteRejectedUpstreamToken(t *models.UpstreamToken) error {
+	_, err := c.Connection.Exec(
+		`DELETE FROM upstream_tokens WHERE user_id = $1 AND provider = $2 AND refresh_token = $3;`,
+		t.UserID,
+		t.Provider,
+		t.RefreshToken,
+	)
+	return err
+}
+
+// ListExpiringUpstreamTokens selects the tokens that can be refreshed and
+// expire before the given time
+func (c *Client) ListExpiringUpstreamTokens(before time.Time) ([]*models.UpstreamToken, error) {
+	query := `
+		SELECT ` + upstreamTokenColumns + `
+		FROM upstream_tokens
+		WHERE expires_at < $1 AND refresh_token <> ''
+		ORDER BY expires_at;
+	`
+	rows, err := c.Connection.Query(query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*models.UpstreamToken{}
+	for rows.Next() {
+		t, err := scanUpstreamToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// DeleteUpstreamToken forgets the tokens a provider issued for a user
+func (c *Client) DeleteUpstreamToken(userID int, provider string) error {
+	res, err := c.Connection.Exec(`DELETE FROM upstream_tokens WHERE user_id = $1 AND provider = $2;`, userID, provider)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func scanUpstreamToken(row scanner) (*models.UpstreamToken, error) {
+	t := models.UpstreamToken{}
+	var expiresAt pq.NullTime
+	err := row.Scan(
+		&t.UserID,
+		&t.Provider,
+		&t.AccessToken,
+		&t.RefreshToken,
+		&t.TokenType,
+		&t.Scope,
+		&t.Created,
+		&t.Updated,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ExpiresAt = expiresAt.Time
+	return &t, nil
+}
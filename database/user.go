@@ -10,8 +10,8 @@ import (
 // CreateUser inserts a new User in the database
 func (c *Client) CreateUser(u *models.User) (int, error) {
 	query := `
-		INSERT INTO users (bio, blog, email, image, location, login, name, fitbit_age, fitbit_avatar150, fitbit_id, fitbit_fullname, fitbit_json_payload, provider, last_login, created)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		INSERT INTO users (bio, blog, email, image, location, login, name, fitbit_age, fitbit_avatar150, fitbit_id, fitbit_fullname, fitbit_json_payload, provider, last_login, created, github_id, github_node_id, email_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		RETURNING id;
 	`
 
@@ -37,6 +37,9 @@ func (c *Client) CreateUser(u *models.User) (int, error) {
 		u.Provider,
 		u.LastLogin,
 		u.Created,
+		u.GHUser.GitHubID,
+		u.GHUser.NodeID,
+		u.GHUser.EmailVerified,
 	).Scan(&id)
 	u.ID = id
 	return id, err
@@ -59,7 +62,10 @@ func (c *Client) UpdateUser(u *models.User) error {
 			fitbit_id = $11,
 			fitbit_fullname = $12,
 			fitbit_json_payload = $13,
-			last_login = $14
+			last_login = $14,
+			github_id = $15,
+			github_node_id = $16,
+			email_verified = $17
 		WHERE id = $1;
 	`
 
@@ -81,48 +87,14 @@ func (c *Client) UpdateUser(u *models.User) error {
 		u.FitBitUser.FullName,
 		u.FitBitUser.RawPayload,
 		u.LastLogin,
+		u.GHUser.GitHubID,
+		u.GHUser.NodeID,
+		u.GHUser.EmailVerified,
 	)
 
 	return err
 }
 
-// GetUserByIdentifier selects an user from his FitBit ID
-func (c *Client) GetUserByIdentifier(column, id string) (*models.User, error) {
-	query := `
-		SELECT id, provider, last_login, created, fitbit_age, fitbit_avatar150, fitbit_fullname, fitbit_id, fitbit_json_payload, bio, blog, email, image, location, login, name
-		FROM users
-		WHERE $1 = $2;
-	`
-	u := models.User{}
-	u.RemoveNils()
-
-	row := c.Connection.QueryRow(query, column, id)
-	if row == nil {
-		return nil, errors.New("Not found")
-	}
-
-	err := row.Scan(
-		&u.ID,
-		&u.Provider,
-		&u.LastLogin,
-		&u.Created,
-		&u.FitBitUser.Age,
-		&u.FitBitUser.Avatar,
-		&u.FitBitUser.FullName,
-		&u.FitBitUser.EncodedID,
-		&u.FitBitUser.RawPayload,
-		&u.GHUser.Bio,
-		&u.GHUser.Blog,
-		&u.GHUser.Email,
-		&u.GHUser.Image,
-		&u.GHUser.Location,
-		&u.GHUser.Login,
-		&u.GHUser.Name,
-	)
-
-	return &u, err
-}
-
 // GetUserByLogin selects an user from his login
 func (c *Client) GetUserByLogin(login, provider string) (*models.User, error) {
 	query := `
@@ -155,3 +127,41 @@ func (c *Client) GetUserByLogin(login, provider string) (*models.User, error) {
 
 	return &u, err
 }
+
+// GetUserByID selects an user from his ID
+func (c *Client) GetUserByID(id int) (*models.User, error) {
+	query := `
+		SELECT id, provider, last_login, created, fitbit_age, fitbit_avatar150, fitbit_fullname, fitbit_id, fitbit_json_payload, bio, blog, email, image, location, login, name, github_id, github_node_id, email_verified
+		FROM users
+		WHERE id = $1;
+	`
+	u := models.User{}
+	u.RemoveNils()
+
+	err := c.Connection.QueryRow(query, id).Scan(
+		&u.ID,
+		&u.Provider,
+		&u.LastLogin,
+		&u.Created,
+		&u.FitBitUser.Age,
+		&u.FitBitUser.Avatar,
+		&u.FitBitUser.FullName,
+		&u.FitBitUser.EncodedID,
+		&u.FitBitUser.RawPayload,
+		&u.GHUser.Bio,
+		&u.GHUser.Blog,
+		&u.GHUser.Email,
+		&u.GHUser.Image,
+		&u.GHUser.Location,
+		&u.GHUser.Login,
+		&u.GHUser.Name,
+		&u.GHUser.GitHubID,
+		&u.GHUser.NodeID,
+		&u.GHUser.EmailVerified,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
@@ -0,0 +1,159 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// JWK : a public JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet : a JWK Set, as published on jwks_uri
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// KeyFor returns the public key a token header refers to by its kid, to be
+// given to Verify
+func (s *JWKSet) KeyFor(header *Header) (crypto.PublicKey, error) {
+	for _, k := range s.Keys {
+		if k.Kid == header.Kid {
+			return k.PublicKey()
+		}
+	}
+	return nil, errors.New("unknown key " + header.Kid)
+}
+
+// NewJWK returns the JWK of a RSA or P-256 public key, identified by its
+// thumbprint
+func NewJWK(pub crypto.PublicKey) (*JWK, error) {
+	var jwk JWK
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		jwk = JWK{
+			Kty: "RSA",
+			Alg: RS256,
+			N:   encode(key.N.Bytes()),
+			E:   encode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 EC keys are supported")
+		}
+		jwk = JWK{
+			Kty: "EC",
+			Alg: ES256,
+			Crv: "P-256",
+			X:   encode(pad(key.X.Bytes(), 32)),
+			Y:   encode(pad(key.Y.Bytes(), 32)),
+		}
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+
+	jwk.Use = "sig"
+	jwk.Kid = jwk.Thumbprint()
+	return &jwk, nil
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of the key
+func (k *JWK) Thumbprint() string {
+	// the required members, in lexicographic order
+	var members interface{}
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return encode(sum[:])
+}
+
+// PublicKey returns the Go public key described by the JWK
+func (k *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, errors.New("only P-256 EC keys are supported")
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, errors.New("unsupported key type " + k.Kty)
+	}
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// pad left-pads big-endian integers to their fixed size
+func pad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
@@ -0,0 +1,144 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Signing algorithms (RFC 7518 section 3.1)
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// Header : JOSE header of a signed token
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Algorithm returns the algorithm used to sign with a RSA or EC private key
+func Algorithm(key crypto.Signer) (string, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return RS256, nil
+	case *ecdsa.PrivateKey:
+		return ES256, nil
+	default:
+		return "", errors.New("unsupported key type")
+	}
+}
+
+// Sign serializes the claims in a compact JWS (RFC 7515), signed by the key
+// identified by kid
+func Sign(claims interface{}, key crypto.Signer, kid string) (string, error) {
+	return SignWithType(claims, key, kid, "JWT")
+}
+
+// SignWithType is Sign with an explicit typ header, for tokens that mustn't
+// be confused with other JWTs (RFC 8725 section 3.11)
+func SignWithType(claims interface{}, key crypto.Signer, kid, typ string) (string, error) {
+	alg, err := Algorithm(key)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(Header{Alg: alg, Kid: kid, Typ: typ})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		// JWS uses the raw R || S form instead of ASN.1 (RFC 7518 section 3.4)
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		if err == nil {
+			signature = append(pad(r.Bytes(), 32), pad(s.Bytes(), 32)...)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + encode(signature), nil
+}
+
+// Verify checks the signature of a compact JWS with the key returned for its
+// header, and decodes its claims. Claims like exp or aud are left to the caller.
+func Verify(token string, keyFor func(*Header) (crypto.PublicKey, error), claims interface{}) (*Header, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	rawHeader, err := decode(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed header: %v", err)
+	}
+	var header Header
+	if err = json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("malformed header: %v", err)
+	}
+
+	signature, err := decode(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %v", err)
+	}
+
+	key, err := keyFor(&header)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if header.Alg != RS256 {
+			return nil, errors.New("unexpected algorithm " + header.Alg)
+		}
+		if err = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if header.Alg != ES256 || len(signature) != 64 {
+			return nil, errors.New("unexpected algorithm " + header.Alg)
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(k, digest[:], r, s) {
+			return nil, errors.New("invalid signature")
+		}
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+
+	payload, err := decode(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed payload: %v", err)
+	}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("malformed payload: %v", err)
+	}
+
+	return &header, nil
+}
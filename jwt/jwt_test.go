@@ -0,0 +1,84 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testClaims struct {
+	Sub string `json:"sub"`
+	Aud string `json:"aud"`
+}
+
+func roundTrip(t *testing.T, key crypto.Signer) {
+	jwk, err := NewJWK(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := Sign(testClaims{Sub: "42", Aud: "app"}, key, jwk.Kid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFor := func(h *Header) (crypto.PublicKey, error) {
+		if h.Kid != jwk.Kid {
+			return nil, errors.New("unknown kid")
+		}
+		return jwk.PublicKey()
+	}
+
+	var claims testClaims
+	header, err := Verify(token, keyFor, &claims)
+	if err != nil {
+		t.Fatalf("verifying the token failed: %v", err)
+	}
+	if claims.Sub != "42" || claims.Aud != "app" || header.Alg != jwk.Alg {
+		t.Errorf("unexpected token %+v %+v", header, claims)
+	}
+
+	// tamper with the payload
+	parts := strings.Split(token, ".")
+	forged, _ := Sign(testClaims{Sub: "1", Aud: "app"}, key, jwk.Kid)
+	parts[1] = strings.Split(forged, ".")[1]
+	if _, err = Verify(strings.Join(parts, "."), keyFor, &claims); err == nil {
+		t.Error("a tampered token should be rejected")
+	}
+}
+
+func TestRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, key)
+}
+
+func TestES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, key)
+}
+
+// Example from RFC 7638 section 3.1
+func TestThumbprint(t *testing.T) {
+	jwk := &JWK{
+		Kty: "RSA",
+		E:   "AQAB",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECP" +
+			"ebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2Q" +
+			"vzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQF" +
+			"h6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+	if jwk.Thumbprint() != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("unexpected thumbprint %s", jwk.Thumbprint())
+	}
+}
@@ -0,0 +1,184 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// Policy : when signing keys are created, activated and retired
+type Policy struct {
+	// Algorithm of the new keys, RS256 or ES256
+	Algorithm string
+
+	// RotationPeriod : how long a key stays active
+	RotationPeriod time.Duration
+
+	// PublishDelay : how long a key is published before being activated, it
+	// must exceed the time clients cache the JWKS
+	PublishDelay time.Duration
+
+	// MaxTokenLifetime : how long a key stays published once replaced, it must
+	// exceed the lifetime of any token it signed
+	MaxTokenLifetime time.Duration
+}
+
+// Generate creates a new pending key, with its private part encrypted
+func Generate(alg string) (*models.SigningKey, error) {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	switch alg {
+	case jwt.RS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.ES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %s", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	jwk, err := jwt.NewJWK(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	public, err := json.Marshal(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	pem, err := jwt.MarshalPrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+	private, err := Seal(pem)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		ID:         jwk.Kid,
+		Algorithm:  alg,
+		Status:     models.KeyPending,
+		PublicKey:  string(public),
+		PrivateKey: private,
+	}, nil
+}
+
+// Active returns the key tokens must be signed with, and its ID
+func Active(db *database.Client) (crypto.Signer, string, error) {
+	active, err := db.GetSigningKeys(models.KeyActive)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(active) == 0 {
+		return nil, "", errors.New("no active signing key, the keys should be rotated")
+	}
+
+	pem, err := Open(active[0].PrivateKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("decrypting the signing key failed: %v", err)
+	}
+
+	signer, err := jwt.ParsePrivateKey(pem)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return signer, active[0].ID, nil
+}
+
+// Published returns the JWK Set of the keys tokens can be verified with: the
+// active key, the next one and the ones retiring
+func Published(db *database.Client) (*jwt.JWKSet, error) {
+	keys, err := db.GetSigningKeys(models.KeyPending, models.KeyActive, models.KeyRetiring)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &jwt.JWKSet{Keys: []*jwt.JWK{}}
+	for _, k := range keys {
+		var jwk jwt.JWK
+		if err = json.Unmarshal([]byte(k.PublicKey), &jwk); err != nil {
+			return nil, fmt.Errorf("malformed public key %s: %v", k.ID, err)
+		}
+		set.Keys = append(set.Keys, &jwk)
+	}
+
+	return set, nil
+}
+
+// Rotate moves the keys forward in their lifecycle: it retires the keys whose
+// tokens expired, activates the pending key once it has been published long
+// enough and the active key is due, and makes sure a next key is published.
+// It is safe to call it as often as wanted.
+func Rotate(db *database.Client, policy Policy) error {
+	now := time.Now()
+
+	if _, err := db.RetireSigningKeys(now); err != nil {
+		return fmt.Errorf("retiring the old keys failed: %v", err)
+	}
+
+	active, err := db.GetSigningKeys(models.KeyActive)
+	if err != nil {
+		return err
+	}
+	pending, err := db.GetSigningKeys(models.KeyPending)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(active) == 0 && len(pending) > 0:
+		// nothing can be signed yet, no need to wait for the publication
+		err = db.ActivateSigningKey(pending[0].ID, now)
+		pending = pending[1:]
+	case len(active) == 0:
+		err = createAndActivate(db, policy.Algorithm, now)
+	case len(pending) > 0 &&
+		now.Sub(active[0].ActivatedAt) >= policy.RotationPeriod &&
+		now.Sub(pending[len(pending)-1].Created) >= policy.PublishDelay:
+		oldest := pending[len(pending)-1]
+		err = db.ActivateSigningKey(oldest.ID, now.Add(policy.MaxTokenLifetime))
+		pending = pending[:len(pending)-1]
+	}
+	if err != nil {
+		return fmt.Errorf("activating a key failed: %v", err)
+	}
+
+	if len(pending) == 0 {
+		next, err := Generate(policy.Algorithm)
+		if err != nil {
+			return err
+		}
+		if err = db.CreateSigningKey(next); err != nil {
+			return fmt.Errorf("publishing the next key failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func createAndActivate(db *database.Client, alg string, now time.Time) error {
+	k, err := Generate(alg)
+	if err != nil {
+		return err
+	}
+	if err = db.CreateSigningKey(k); err != nil {
+		return err
+	}
+	return db.ActivateSigningKey(k.ID, now)
+}
@@ -0,0 +1,67 @@
+package keys
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/models"
+)
+
+func setMasterKey(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	os.Setenv("MASTER_KEY", base64.StdEncoding.EncodeToString(key))
+}
+
+func TestSealing(t *testing.T) {
+	setMasterKey(t)
+
+	sealed, err := Seal([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := Open(sealed)
+	if err != nil || string(opened) != "secret" {
+		t.Errorf("expected secret, got %s (%v)", opened, err)
+	}
+
+	tampered := []byte(sealed)
+	tampered[len(tampered)-2] ^= 1
+	if _, err = Open(string(tampered)); err == nil {
+		t.Error("tampered data should not be decrypted")
+	}
+}
+
+func TestGeneratingKeys(t *testing.T) {
+	setMasterKey(t)
+
+	for _, alg := range []string{jwt.RS256, jwt.ES256} {
+		k, err := Generate(alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if k.Status != models.KeyPending || k.Algorithm != alg {
+			t.Errorf("unexpected key %+v", k)
+		}
+
+		var jwk jwt.JWK
+		if err = json.Unmarshal([]byte(k.PublicKey), &jwk); err != nil || jwk.Kid != k.ID {
+			t.Errorf("the public key should be the JWK identified by the key ID: %v", err)
+		}
+
+		pem, err := Open(k.PrivateKey)
+		if err != nil {
+			t.Fatalf("the private key should be sealed: %v", err)
+		}
+		if _, err = jwt.ParsePrivateKey(pem); err != nil {
+			t.Errorf("the private key should be a PEM: %v", err)
+		}
+	}
+}
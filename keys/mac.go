@@ -0,0 +1,40 @@
+package keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// macKey derives the HMAC key from the master key, so that the same key isn't
+// used for both encryption and signatures
+func macKey() ([]byte, error) {
+	key, err := masterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte("goauth mac"))
+	return h.Sum(nil), nil
+}
+
+// MAC returns the HMAC-SHA256 of data, keyed by the master key
+func MAC(data []byte) ([]byte, error) {
+	key, err := macKey()
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// CheckMAC returns true if mac is the MAC of data
+func CheckMAC(data, mac []byte) bool {
+	expected, err := MAC(data)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, mac)
+}
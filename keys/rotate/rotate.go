@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+func main() {
+	force := flag.Bool("force", false, "activate the next key now, without waiting for the rotation period")
+	flag.Parse()
+
+	client, err := database.NewClient()
+	if err != nil {
+		fmt.Println("connecting to the database failed: ", err)
+		return
+	}
+
+	policy := oauth.KeyPolicy()
+	if *force {
+		policy.RotationPeriod = 0
+		policy.PublishDelay = 0
+	}
+
+	if err = keys.Rotate(client, policy); err != nil {
+		panic(fmt.Sprintf("rotating the keys failed: %v", err))
+	}
+
+	published, err := keys.Published(client)
+	if err != nil {
+		panic(fmt.Sprintf("reading the keys failed: %v", err))
+	}
+
+	fmt.Println("published keys:")
+	for _, k := range published.Keys {
+		fmt.Printf("%s\t%s\n", k.Kid, k.Alg)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/oauth"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Login or register with Socialement Competents</title>
+</head>
+<body>
+  {{range .}}
+  <a href="?{{.Query}}">Login with {{.Name}}</a>
+  {{end}}
+</body>
+</html>`))
+
+// Authorize : authorization endpoint (RFC 6749 section 3.1), sending the user
+// through an upstream provider login before redirecting him to the client,
+// unless his GOAuth session can be used
+func Authorize(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	params := request.QueryStringParameters
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return respond(
+			http.StatusInternalServerError,
+			fmt.Sprintf("couldn't connect to the db: %v", err.Error()),
+		)
+	}
+
+	// Until the client and its redirection URI are validated, errors must not
+	// redirect the user (RFC 6749 section 4.1.2.1)
+	client, err := dbClient.GetClient(params["client_id"])
+	if err != nil {
+		return respond(http.StatusBadRequest, "unknown client_id")
+	}
+
+	redirectURI, err := oauth.ResolveRedirectURI(client, params["redirect_uri"])
+	if err != nil {
+		return respond(http.StatusBadRequest, err.Error())
+	}
+
+	authRequest, oauthErr := oauth.NewAuthorizationRequest(client, redirectURI, params)
+	if oauthErr != nil {
+		return redirectError(redirectURI, params["state"], oauthErr)
+	}
+
+	// single sign-on: the user already logged in to GOAuth for another client
+	if session := oauth.CurrentSession(dbClient, &request); oauth.CanUseSession(client, authRequest, session) {
+		if err = dbClient.CreateAuthorizationRequest(authRequest); err != nil {
+			return redirectError(
+				redirectURI,
+				params["state"],
+				oauth.NewError(oauth.ServerError, "couldn't save the authorization request"),
+			)
+		}
+
+		location, err := oauth.CompleteAuthorization(dbClient, authRequest.ID, session)
+		if err != nil {
+			return respond(http.StatusBadRequest, err.Error())
+		}
+		return oauth.Redirect(location)
+	}
+
+	if authRequest.Prompt == oauth.PromptNone {
+		return redirectError(
+			redirectURI,
+			params["state"],
+			oauth.NewError(oauth.LoginRequired, "the user isn't logged in"),
+		)
+	}
+
+	if authRequest.Provider == "" {
+		return loginPageResponse(client, params)
+	}
+
+	loginURL, err := providers.LoginURL(authRequest.Provider, authRequest.ID)
+	if err != nil {
+		return redirectError(
+			redirectURI,
+			params["state"],
+			oauth.NewError(oauth.InvalidRequest, err.Error()),
+		)
+	}
+
+	if err = dbClient.CreateAuthorizationRequest(authRequest); err != nil {
+		return redirectError(
+			redirectURI,
+			params["state"],
+			oauth.NewError(oauth.ServerError, "couldn't save the authorization request"),
+		)
+	}
+
+	return oauth.Redirect(loginURL)
+}
+
+type loginLink struct {
+	Name  string
+	Query template.URL
+}
+
+// Lets the user pick one of the client providers, by replaying the request
+// with the provider set
+func loginPageResponse(client *models.Client, params map[string]string) (events.APIGatewayProxyResponse, error) {
+	links := []loginLink{}
+	for _, provider := range client.Providers {
+		query := url.Values{}
+		for key, value := range params {
+			query.Set(key, value)
+		}
+		query.Set("provider", provider)
+
+		links = append(links, loginLink{
+			Name:  providers.DisplayName(provider),
+			Query: template.URL(query.Encode()),
+		})
+	}
+
+	var page bytes.Buffer
+	err := loginPage.Execute(&page, links)
+	if err != nil {
+		return respond(http.StatusInternalServerError, err.Error())
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8"},
+		Body:       page.String(),
+	}, nil
+}
+
+func redirectError(redirectURI, state string, e *oauth.Error) (events.APIGatewayProxyResponse, error) {
+	location, err := oauth.ErrorRedirectURL(redirectURI, state, e)
+	if err != nil {
+		return respond(http.StatusBadRequest, e.Error())
+	}
+	return oauth.Redirect(location)
+}
+
+func respond(code int, payload interface{}) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Body:       fmt.Sprint(payload),
+	}, nil
+}
+
+func main() {
+	lambda.Start(Authorize)
+}
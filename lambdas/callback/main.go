@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/oauth"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+// HandleCallback : handles the callback of any provider, on
+// /callback/{provider}: the user is created or updated from his upstream
+// profile, then sent back to the client application that asked him to log in.
+// When a logged in user started a link through /link, the provider account is
+// linked to him instead.
+func HandleCallback(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	name := request.PathParameters["provider"]
+	if name == "" {
+		name = request.QueryStringParameters["provider"]
+	}
+
+	provider, err := providers.Get(name)
+	if err != nil {
+		return respond(http.StatusNotFound, err.Error())
+	}
+
+	params := request.QueryStringParameters
+	state := params["state"]
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return respond(
+			http.StatusInternalServerError,
+			fmt.Sprintf("couldn't connect to the db: %v", err.Error()),
+		)
+	}
+
+	// only the logins GOAuth started are completed: the state binds the
+	// callback to them
+	if state == "" {
+		return respond(http.StatusBadRequest, "the state is missing: the login must be started by GOAuth")
+	}
+
+	// a logged in user is linking the account through /link
+	if link, err := dbClient.GetLinkRequest(state); err == nil {
+		session := oauth.CurrentSession(dbClient, &request)
+		user, err := oauth.CompleteLink(dbClient, link, session, provider, params)
+		if err != nil {
+			return respond(http.StatusBadRequest, fmt.Sprintf("linking the account failed: %v", err))
+		}
+		return respondUser(user, "linked", http.StatusOK)
+	}
+
+	// the user refused to log in, or the provider failed
+	if params["error"] != "" {
+		location, err := oauth.AbortAuthorization(
+			dbClient,
+			state,
+			oauth.NewError(oauth.AccessDenied, provider.DisplayName()+" login failed: "+params["error"]),
+		)
+		if err != nil {
+			return respond(http.StatusBadRequest, err.Error())
+		}
+		return oauth.Redirect(location)
+	}
+
+	// the user logs in for a client application through /authorize: the
+	// provider must call back for a request GOAuth started with it
+	if err = oauth.CheckLoginState(dbClient, state, provider.Name()); err != nil {
+		return respond(http.StatusBadRequest, err.Error())
+	}
+
+	user, _, err := providers.Login(dbClient, provider, params)
+	if err != nil {
+		return respond(http.StatusInternalServerError, err.Error())
+	}
+
+	resp, err := oauth.CompleteLogin(dbClient, state, user, provider.Name())
+	if err != nil {
+		return respond(
+			http.StatusBadRequest,
+			fmt.Sprintf("completing the authorization failed: %v", err),
+		)
+	}
+	return resp, nil
+}
+
+func respondUser(user *models.User, verb string, statusCode int) (events.APIGatewayProxyResponse, error) {
+	jsonBytes, err := json.Marshal(user)
+	if err != nil {
+		text := fmt.Sprintf("user %s but could not format to JSON: %v", verb, err)
+		return respond(http.StatusAccepted, text)
+	}
+
+	return respond(statusCode, string(jsonBytes))
+}
+
+func respond(code int, payload interface{}) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Body:       fmt.Sprint(payload),
+	}, nil
+}
+
+func main() {
+	lambda.Start(HandleCallback)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+var consentPage = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Authorize {{.ClientName}}</title>
+</head>
+<body>
+  {{if .UserCode}}
+  <p>A device wants to sign in as you with the code <strong>{{.UserCode}}</strong>.
+  Only allow it if this code is shown on a device you are using.</p>
+  {{end}}
+  <p>{{.ClientName}} would like to:</p>
+  <ul>
+    {{range .Scopes}}
+    <li>{{.Description}}</li>
+    {{end}}
+  </ul>
+  <form method="POST">
+    <input type="hidden" name="request" value="{{.RequestID}}" />
+    <input type="hidden" name="csrf_token" value="{{.CSRFToken}}" />
+    <button type="submit" name="decision" value="allow">Allow</button>
+    <button type="submit" name="decision" value="deny">Deny</button>
+  </form>
+</body>
+</html>`))
+
+// HandleConsent : asks the user who just logged in whether the client can
+// access his account with the requested scope, and completes the
+// authorization request with his decision
+func HandleConsent(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return respond(http.StatusInternalServerError, "couldn't connect to the db")
+	}
+
+	// only the session that logged in for the request can decide
+	session := oauth.CurrentSession(dbClient, &request)
+
+	if request.HTTPMethod != http.MethodPost {
+		prompt, err := oauth.GetConsentPrompt(dbClient, request.QueryStringParameters["request"], session)
+		if err != nil {
+			return respond(http.StatusBadRequest, err.Error())
+		}
+
+		var page bytes.Buffer
+		if err = consentPage.Execute(&page, prompt); err != nil {
+			return respond(http.StatusInternalServerError, err.Error())
+		}
+
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers: map[string]string{
+				"Content-Type":    "text/html; charset=utf-8",
+				"X-Frame-Options": "DENY",
+			},
+			Body: page.String(),
+		}, nil
+	}
+
+	form, err := oauth.ParseForm(&request)
+	if err != nil {
+		return respond(http.StatusBadRequest, "malformed form body")
+	}
+
+	location, err := oauth.DecideConsent(dbClient, form.Get("request"), form.Get("csrf_token"), session, form.Get("decision") == "allow")
+	if err != nil {
+		return respond(http.StatusBadRequest, err.Error())
+	}
+
+	return oauth.Redirect(location)
+}
+
+func respond(code int, body string) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Body:       body,
+	}, nil
+}
+
+func main() {
+	lambda.Start(HandleConsent)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleConsents : lists the consents the user of the bearer token gave
+// (GET), or withdraws the consent given to client_id (DELETE), revoking the
+// tokens of that client. A client can withdraw its own consent, first-party
+// clients (our account pages) can withdraw any.
+func HandleConsents(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	token, oauthErr := oauth.AuthenticateBearer(dbClient, &request)
+	if oauthErr != nil {
+		return oauth.BearerErrorResponse(oauthErr, "")
+	}
+	if token.UserID == 0 {
+		return oauth.BearerErrorResponse(oauth.NewError(oauth.InvalidToken, "the token wasn't issued on behalf of a user"), "")
+	}
+
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		consents, err := dbClient.ListConsents(token.UserID)
+		if err != nil {
+			return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't list the consents"))
+		}
+		return oauth.JSONResponse(http.StatusOK, consents)
+
+	case http.MethodDelete:
+		clientID := request.QueryStringParameters["client_id"]
+		if id, ok := request.PathParameters["client_id"]; ok {
+			clientID = id
+		}
+		if clientID == "" {
+			return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "client_id is required"))
+		}
+
+		if clientID != token.ClientID {
+			client, err := dbClient.GetClient(token.ClientID)
+			if err != nil || !client.FirstParty {
+				return oauth.JSONResponse(http.StatusForbidden, oauth.NewError(oauth.UnauthorizedClient, "a client can only withdraw its own consent"))
+			}
+		}
+
+		err = dbClient.WithdrawConsent(token.UserID, clientID)
+		if err == sql.ErrNoRows {
+			return oauth.JSONResponse(http.StatusNotFound, oauth.NewError("not_found", "no consent was given to this client"))
+		}
+		if err != nil {
+			return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't withdraw the consent"))
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent}, nil
+
+	default:
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "only GET and DELETE are supported"))
+	}
+}
+
+func main() {
+	lambda.Start(HandleConsents)
+}
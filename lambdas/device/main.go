@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/oauth"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+var devicePage = template.Must(template.New("device").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Connect a device to Socialement Competents</title>
+</head>
+<body>
+  {{if .Approved}}
+  <p>Your device is connected, you can go back to it.</p>
+  {{else}}
+  {{if .Error}}<p>{{.Error}}</p>{{end}}
+  <form method="GET">
+    <label>Code shown on your device <input name="user_code" value="{{.UserCode}}" autocomplete="off" /></label>
+    {{if .LoggedInWith}}
+    <button type="submit">Continue with your {{.LoggedInWith}} account</button>
+    {{else}}
+    {{range .Providers}}
+    <button type="submit" name="provider" value="{{.ID}}">Login with {{.Name}}</button>
+    {{end}}
+    {{end}}
+  </form>
+  {{end}}
+</body>
+</html>`))
+
+type provider struct {
+	ID   string
+	Name string
+}
+
+type page struct {
+	UserCode  string
+	Error     string
+	Approved  bool
+	Providers []provider
+
+	// The provider of the user's GOAuth session, who doesn't have to log in
+	// again
+	LoggedInWith string
+}
+
+// HandleDevice : verification page of the device grant (RFC 8628 section
+// 3.3), where the user enters the code shown by his device and logs in, or
+// continues with his GOAuth session
+func HandleDevice(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	params := request.QueryStringParameters
+	p := page{
+		UserCode: params["user_code"],
+		Error:    params["error"],
+		Approved: params["approved"] == "true",
+	}
+	for _, id := range models.Providers {
+		p.Providers = append(p.Providers, provider{ID: id, Name: providers.DisplayName(id)})
+	}
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		p.Error = "the service is unavailable, please try again later"
+		return render(http.StatusInternalServerError, &p)
+	}
+
+	session := oauth.CurrentSession(dbClient, &request)
+	if session != nil {
+		p.LoggedInWith = providers.DisplayName(session.Provider)
+	}
+
+	if p.UserCode == "" || (params["provider"] == "" && session == nil) {
+		return render(http.StatusOK, &p)
+	}
+
+	location, err := oauth.StartDeviceLogin(dbClient, p.UserCode, params["provider"], session)
+	if err != nil {
+		// another account may be allowed for this application
+		p.Error = err.Error()
+		p.LoggedInWith = ""
+		return render(http.StatusBadRequest, &p)
+	}
+
+	return oauth.Redirect(location)
+}
+
+func render(code int, p *page) (events.APIGatewayProxyResponse, error) {
+	var body bytes.Buffer
+	if err := devicePage.Execute(&body, p); err != nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError, Body: err.Error()}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8"},
+		Body:       body.String(),
+	}, nil
+}
+
+func main() {
+	lambda.Start(HandleDevice)
+}
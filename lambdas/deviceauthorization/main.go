@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleDeviceAuthorization : device authorization endpoint (RFC 8628 section
+// 3.1), giving CLI tools the codes to show the user and to poll with
+func HandleDeviceAuthorization(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if request.HTTPMethod != http.MethodPost {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "the device authorization endpoint only accepts POST"))
+	}
+
+	form, err := oauth.ParseForm(&request)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "malformed form body"))
+	}
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	client, oauthErr := oauth.AuthenticateClient(dbClient, &request, form)
+	if oauthErr != nil {
+		return oauth.ErrorResponse(oauthErr)
+	}
+
+	resp, oauthErr := oauth.NewDeviceAuthorization(dbClient, client, form)
+	if oauthErr != nil {
+		return oauth.ErrorResponse(oauthErr)
+	}
+
+	return oauth.JSONResponse(http.StatusOK, resp)
+}
+
+func main() {
+	lambda.Start(HandleDeviceAuthorization)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleDiscovery : serves /.well-known/openid-configuration, describing the
+// endpoints and capabilities of the server
+func HandleDiscovery(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	published, err := keys.Published(dbClient)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't read the signing keys"))
+	}
+
+	metadata, err := oauth.NewMetadata(published)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, err.Error()))
+	}
+
+	return oauth.PublicJSONResponse(metadata)
+}
+
+func main() {
+	lambda.Start(HandleDiscovery)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+const loggedOutPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Logged out of Socialement Competents</title>
+</head>
+<body>
+  <p>You are logged out.</p>
+</body>
+</html>`
+
+var confirmPage = template.Must(template.New("logout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Log out of Socialement Competents</title>
+</head>
+<body>
+  <p>Do you want to log out of Socialement Competents?</p>
+  <form method="POST">
+    {{range $name, $value := .}}
+    <input type="hidden" name="{{$name}}" value="{{$value}}" />
+    {{end}}
+    <button type="submit">Log out</button>
+  </form>
+</body>
+</html>`))
+
+// HandleEndSession : end_session endpoint (OpenID Connect RP-Initiated
+// Logout), ending the GOAuth session of the user and notifying the clients
+// he used during it, before sending him back to the client. Without an
+// id_token_hint, the user confirms he wants to log out first.
+func HandleEndSession(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	params, err := getParams(&request)
+	if err != nil {
+		return respond(http.StatusBadRequest, "malformed form body")
+	}
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return respond(http.StatusInternalServerError, "couldn't connect to the db")
+	}
+
+	var hint *oauth.IDTokenClaims
+	if params.Get("id_token_hint") != "" {
+		if hint, err = oauth.ParseIDTokenHint(dbClient, params.Get("id_token_hint")); err != nil {
+			return respond(http.StatusBadRequest, "invalid id_token_hint")
+		}
+	}
+
+	clientID := params.Get("client_id")
+	if hint != nil {
+		if clientID != "" && clientID != hint.Audience {
+			return respond(http.StatusBadRequest, "client_id doesn't match the id_token_hint")
+		}
+		clientID = hint.Audience
+	}
+
+	// the redirection URI must be registered, or logging out would be an
+	// open redirector
+	redirectURI := params.Get("post_logout_redirect_uri")
+	if redirectURI != "" {
+		if clientID == "" {
+			return respond(http.StatusBadRequest, "post_logout_redirect_uri requires id_token_hint or client_id")
+		}
+		client, err := dbClient.GetClient(clientID)
+		if err != nil || !client.HasPostLogoutRedirectURI(redirectURI) {
+			return respond(http.StatusBadRequest, "post_logout_redirect_uri is not registered for this client")
+		}
+	}
+
+	session := oauth.CurrentSession(dbClient, &request)
+	if session == nil && hint != nil && hint.SID != "" {
+		if s, err := dbClient.GetSessionBySID(hint.SID); err == nil {
+			session = s
+		}
+	}
+	if session != nil && hint != nil && oauth.Subject(session.UserID) != hint.Subject {
+		return respond(http.StatusBadRequest, "the id_token_hint was issued to another user")
+	}
+
+	if session != nil && hint == nil && !oauth.CheckLogoutConfirmation(session, params.Get("csrf_token")) {
+		return confirmLogout(session, params)
+	}
+
+	if session != nil {
+		if err = oauth.EndSession(dbClient, session); err != nil {
+			return respond(http.StatusInternalServerError, "couldn't end the session")
+		}
+	}
+
+	if redirectURI == "" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers: map[string]string{
+				"Content-Type": "text/html; charset=utf-8",
+				"Set-Cookie":   oauth.EndSessionCookie(),
+			},
+			Body: loggedOutPage,
+		}, nil
+	}
+
+	location, err := oauth.RedirectURL(redirectURI, url.Values{"state": {params.Get("state")}})
+	if err != nil {
+		return respond(http.StatusBadRequest, err.Error())
+	}
+
+	resp, err := oauth.Redirect(location)
+	resp.Headers["Set-Cookie"] = oauth.EndSessionCookie()
+	return resp, err
+}
+
+// confirmLogout asks the user to confirm he wants to log out, posting back
+// the request parameters
+func confirmLogout(session *models.Session, params url.Values) (events.APIGatewayProxyResponse, error) {
+	csrfToken, err := oauth.LogoutConfirmationToken(session)
+	if err != nil {
+		return respond(http.StatusInternalServerError, err.Error())
+	}
+
+	fields := map[string]string{"csrf_token": csrfToken}
+	for _, name := range []string{"client_id", "post_logout_redirect_uri", "state"} {
+		if value := params.Get(name); value != "" {
+			fields[name] = value
+		}
+	}
+
+	var page bytes.Buffer
+	if err = confirmPage.Execute(&page, fields); err != nil {
+		return respond(http.StatusInternalServerError, err.Error())
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Type":    "text/html; charset=utf-8",
+			"X-Frame-Options": "DENY",
+		},
+		Body: page.String(),
+	}, nil
+}
+
+// getParams reads the query of a GET request, or the form of a POST one
+func getParams(request *events.APIGatewayProxyRequest) (url.Values, error) {
+	if request.HTTPMethod == http.MethodPost {
+		return oauth.ParseForm(request)
+	}
+
+	params := url.Values{}
+	for key, value := range request.QueryStringParameters {
+		params.Set(key, value)
+	}
+	return params, nil
+}
+
+func respond(code int, body string) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Body:       body,
+	}, nil
+}
+
+func main() {
+	lambda.Start(HandleEndSession)
+}
@@ -2,21 +2,82 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
 )
 
-// Event : incoming event
+// Event : incoming event, the ID of the user to get (admin tokens only)
 type Event struct {
-	ID int `json:"id"`
+	ID int `json:"id,string"`
 }
 
-// HandleRequest : handle the incoming requests
+// HandleRequest : returns the user the bearer token was issued for, or any
+// user for our services' client credentials tokens with the admin scope,
+// with the fields allowed by the token scope
 func HandleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	_ = models.User{}
-	panic("NOT IMPLEMENTED")
+	event, err := getEvent(&request)
+	if err != nil {
+		return oauth.BearerErrorResponse(oauth.NewError(oauth.InvalidRequest, "id must be a number"), "")
+	}
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	token, oauthErr := oauth.AuthenticateBearer(dbClient, &request)
+	if oauthErr != nil {
+		return oauth.BearerErrorResponse(oauthErr, "")
+	}
+
+	// tokens issued to a client on its own behalf have no current user
+	if event.ID == 0 && token.UserID == 0 {
+		return oauth.BearerErrorResponse(oauth.NewError(oauth.InvalidRequest, "id is required for client tokens"), "")
+	}
+
+	userID := token.UserID
+	if event.ID != 0 && event.ID != token.UserID {
+		if oauthErr = oauth.RequireAdmin(token); oauthErr != nil {
+			return oauth.BearerErrorResponse(oauthErr, oauth.ScopeAdmin)
+		}
+		userID = event.ID
+	}
+
+	user, err := dbClient.GetUserByID(userID)
+	if err == sql.ErrNoRows {
+		return oauth.JSONResponse(http.StatusNotFound, oauth.NewError("not_found", "no such user"))
+	}
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't get the user"))
+	}
+
+	return oauth.JSONResponse(http.StatusOK, oauth.FilterUser(user, token))
+}
+
+func getEvent(request *events.APIGatewayProxyRequest) (*Event, error) {
+	params := request.QueryStringParameters
+	if id, ok := request.PathParameters["id"]; ok {
+		params = map[string]string{"id": id}
+	}
+
+	var event Event
+	if params["id"] == "" {
+		return &event, nil
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &event)
+	return &event, err
 }
 
 func main() {
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+// HandleIdentities : lists the provider accounts linked to the user of the
+// bearer token (GET), or unlinks the account of provider (DELETE). Listing
+// requires the profile scope, except for first-party clients (our account
+// pages), which are the only ones that can unlink.
+func HandleIdentities(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	token, oauthErr := oauth.AuthenticateBearer(dbClient, &request)
+	if oauthErr != nil {
+		return oauth.BearerErrorResponse(oauthErr, "")
+	}
+	if token.UserID == 0 {
+		return oauth.BearerErrorResponse(oauth.NewError(oauth.InvalidToken, "the token wasn't issued on behalf of a user"), "")
+	}
+
+	client, err := dbClient.GetClient(token.ClientID)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't read the client"))
+	}
+
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		// the identities hold the provider profiles
+		if !client.FirstParty {
+			if oauthErr = oauth.RequireScope(token, oauth.ScopeProfile); oauthErr != nil {
+				return oauth.BearerErrorResponse(oauthErr, oauth.ScopeProfile)
+			}
+		}
+
+		identities, err := dbClient.ListIdentities(token.UserID)
+		if err != nil {
+			return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't list the identities"))
+		}
+		return oauth.JSONResponse(http.StatusOK, identities)
+
+	case http.MethodDelete:
+		provider := request.QueryStringParameters["provider"]
+		if p, ok := request.PathParameters["provider"]; ok {
+			provider = p
+		}
+		if provider == "" {
+			return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "provider is required"))
+		}
+		if _, err = providers.Get(provider); err != nil {
+			return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, err.Error()))
+		}
+
+		if !client.FirstParty {
+			return oauth.JSONResponse(http.StatusForbidden, oauth.NewError(oauth.UnauthorizedClient, "only first-party clients can unlink accounts"))
+		}
+
+		err = providers.Unlink(dbClient, token.UserID, provider)
+		if err == sql.ErrNoRows {
+			return oauth.JSONResponse(http.StatusNotFound, oauth.NewError("not_found", "no account of this provider is linked"))
+		}
+		if err == providers.ErrLastIdentity {
+			return oauth.JSONResponse(http.StatusConflict, oauth.NewError(oauth.InvalidRequest, err.Error()))
+		}
+		if err != nil {
+			return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't unlink the account"))
+		}
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent}, nil
+
+	default:
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "only GET and DELETE are supported"))
+	}
+}
+
+func main() {
+	lambda.Start(HandleIdentities)
+}
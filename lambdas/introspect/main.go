@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleIntrospection : token introspection endpoint (RFC 7662), letting our
+// services check the GOAuth tokens they receive
+func HandleIntrospection(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if request.HTTPMethod != http.MethodPost {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "the introspection endpoint only accepts POST"))
+	}
+
+	form, err := oauth.ParseForm(&request)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "malformed form body"))
+	}
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	client, oauthErr := oauth.AuthenticateClient(dbClient, &request, form)
+	if oauthErr != nil {
+		return oauth.ErrorResponse(oauthErr)
+	}
+	if client.Public {
+		return oauth.ErrorResponse(oauth.NewError(oauth.UnauthorizedClient, "public clients can't introspect tokens"))
+	}
+
+	token := form.Get("token")
+	if token == "" {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "token is required"))
+	}
+
+	return oauth.JSONResponse(http.StatusOK, oauth.Introspect(dbClient, token, form.Get("token_type_hint")))
+}
+
+func main() {
+	lambda.Start(HandleIntrospection)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleJWKS : serves /.well-known/jwks.json, the public keys clients verify
+// the tokens with, including the next one
+func HandleJWKS(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	published, err := keys.Published(dbClient)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't read the signing keys"))
+	}
+
+	return oauth.PublicJSONResponse(published)
+}
+
+func main() {
+	lambda.Start(HandleJWKS)
+}
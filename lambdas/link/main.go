@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleLink : sends the user logged in to GOAuth through the login of
+// another provider, given as the provider parameter, to link that account to
+// him. The callback completes the link.
+func HandleLink(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return respond(
+			http.StatusInternalServerError,
+			fmt.Sprintf("couldn't connect to the db: %v", err.Error()),
+		)
+	}
+
+	session := oauth.CurrentSession(dbClient, &request)
+	if session == nil {
+		return respond(http.StatusUnauthorized, "log in before linking another account")
+	}
+
+	provider := request.QueryStringParameters["provider"]
+	if provider == "" {
+		return respond(http.StatusBadRequest, "provider is required")
+	}
+
+	loginURL, err := oauth.StartLink(dbClient, session, provider)
+	if err != nil {
+		return respond(http.StatusBadRequest, err.Error())
+	}
+
+	return oauth.Redirect(loginURL)
+}
+
+func respond(code int, payload interface{}) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Body:       fmt.Sprint(payload),
+	}, nil
+}
+
+func main() {
+	lambda.Start(HandleLink)
+}
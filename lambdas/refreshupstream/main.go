@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+// tokens expiring before the next runs are refreshed
+const refreshWithin = time.Hour
+
+// HandleRefresh : scheduled lambda (CloudWatch Events) refreshing the provider
+// tokens before they expire. It is meant to run every 15 minutes.
+func HandleRefresh(ctx context.Context, event events.CloudWatchEvent) error {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return err
+	}
+
+	failed, err := providers.RefreshExpiringTokens(dbClient, refreshWithin)
+	if err != nil {
+		log.Println("refreshing the provider tokens failed: ", err)
+		return err
+	}
+	if failed > 0 {
+		log.Printf("%d provider tokens couldn't be refreshed", failed)
+	}
+
+	return nil
+}
+
+func main() {
+	lambda.Start(HandleRefresh)
+}
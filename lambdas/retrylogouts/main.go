@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleRetry : scheduled lambda (CloudWatch Events) sending the back-channel
+// logout notifications queued when sessions end, and again the ones that
+// failed. It is meant to run every minute.
+func HandleRetry(ctx context.Context, event events.CloudWatchEvent) error {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return err
+	}
+
+	failed, err := oauth.RetryLogoutDeliveries(dbClient)
+	if err != nil {
+		log.Println("retrying the logout notifications failed: ", err)
+		return err
+	}
+	if failed > 0 {
+		log.Printf("%d logout notifications failed", failed)
+	}
+
+	return nil
+}
+
+func main() {
+	lambda.Start(HandleRetry)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleRevocation : token revocation endpoint (RFC 7009), called by our apps
+// when their users log out
+func HandleRevocation(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if request.HTTPMethod != http.MethodPost {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "the revocation endpoint only accepts POST"))
+	}
+
+	form, err := oauth.ParseForm(&request)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "malformed form body"))
+	}
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	client, oauthErr := oauth.AuthenticateClient(dbClient, &request, form)
+	if oauthErr != nil {
+		return oauth.ErrorResponse(oauthErr)
+	}
+
+	token := form.Get("token")
+	if token == "" {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "token is required"))
+	}
+
+	if oauthErr = oauth.Revoke(dbClient, client, token, form.Get("token_type_hint")); oauthErr != nil {
+		return oauth.ErrorResponse(oauthErr)
+	}
+
+	return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+}
+
+func main() {
+	lambda.Start(HandleRevocation)
+}
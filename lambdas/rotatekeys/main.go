@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleRotation : scheduled lambda (CloudWatch Events) rotating the signing
+// keys. It is meant to run daily; keys are only replaced when they are due.
+func HandleRotation(ctx context.Context, event events.CloudWatchEvent) error {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return err
+	}
+
+	if err = keys.Rotate(dbClient, oauth.KeyPolicy()); err != nil {
+		log.Println("rotating the signing keys failed: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func main() {
+	lambda.Start(HandleRotation)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleToken : token endpoint (RFC 6749 section 3.2), exchanging grants for
+// GOAuth access tokens
+func HandleToken(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if request.HTTPMethod != http.MethodPost {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "the token endpoint only accepts POST"))
+	}
+
+	form, err := oauth.ParseForm(&request)
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "malformed form body"))
+	}
+
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	client, oauthErr := oauth.AuthenticateClient(dbClient, &request, form)
+	if oauthErr != nil {
+		return oauth.ErrorResponse(oauthErr)
+	}
+
+	grantType := form.Get("grant_type")
+	handler, ok := oauth.Grants[grantType]
+	switch {
+	case grantType == "":
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "grant_type is required"))
+	case !ok:
+		return oauth.ErrorResponse(oauth.NewError(oauth.UnsupportedGrantType, grantType+" is not supported"))
+	case !client.AllowsGrantType(grantType):
+		return oauth.ErrorResponse(oauth.NewError(oauth.UnauthorizedClient, grantType+" is not allowed for this client"))
+	}
+
+	resp, oauthErr := handler(dbClient, client, form)
+	if oauthErr != nil {
+		return oauth.ErrorResponse(oauthErr)
+	}
+
+	return oauth.JSONResponse(http.StatusOK, resp)
+}
+
+func main() {
+	lambda.Start(HandleToken)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleUpstreamToken : returns a fresh access token of the provider given as
+// the provider parameter, for the user of the bearer token. The client the
+// token was issued to must be allowed to call the provider
+// (-upstream-providers of the clients command), and the token must have been
+// granted the upstream:<provider> scope.
+func HandleUpstreamToken(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	token, oauthErr := oauth.AuthenticateBearer(dbClient, &request)
+	if oauthErr != nil {
+		return oauth.BearerErrorResponse(oauthErr, "")
+	}
+
+	provider := request.QueryStringParameters["provider"]
+	if p, ok := request.PathParameters["provider"]; ok {
+		provider = p
+	}
+	if provider == "" {
+		return oauth.ErrorResponse(oauth.NewError(oauth.InvalidRequest, "provider is required"))
+	}
+
+	resp, oauthErr := oauth.GetUpstreamToken(dbClient, token, provider)
+	switch {
+	case oauthErr == nil:
+		return oauth.JSONResponse(http.StatusOK, resp)
+	case oauthErr.Code == oauth.InvalidToken:
+		return oauth.BearerErrorResponse(oauthErr, "")
+	case oauthErr.Code == oauth.InsufficientScope:
+		return oauth.BearerErrorResponse(oauthErr, oauth.UpstreamScope(provider))
+	case oauthErr.Code == oauth.UnauthorizedClient:
+		return oauth.JSONResponse(http.StatusForbidden, oauthErr)
+	default:
+		return oauth.ErrorResponse(oauthErr)
+	}
+}
+
+func main() {
+	lambda.Start(HandleUpstreamToken)
+}
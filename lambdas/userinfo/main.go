@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/oauth"
+)
+
+// HandleUserInfo : OpenID Connect UserInfo endpoint, returning the standard
+// claims of the user the access token was issued for
+func HandleUserInfo(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dbClient, err := database.NewClient()
+	if err != nil {
+		return oauth.ErrorResponse(oauth.NewError(oauth.ServerError, "couldn't connect to the db"))
+	}
+
+	token, oauthErr := oauth.AuthenticateBearer(dbClient, &request)
+	if oauthErr != nil {
+		return oauth.BearerErrorResponse(oauthErr, oauth.ScopeOpenID)
+	}
+
+	if oauthErr = oauth.RequireScope(token, oauth.ScopeOpenID); oauthErr != nil {
+		return oauth.BearerErrorResponse(oauthErr, oauth.ScopeOpenID)
+	}
+
+	if token.UserID == 0 {
+		return oauth.BearerErrorResponse(
+			oauth.NewError(oauth.InvalidToken, "the token wasn't issued for a user"),
+			oauth.ScopeOpenID,
+		)
+	}
+
+	user, err := dbClient.GetUserByID(token.UserID)
+	if err != nil {
+		return oauth.BearerErrorResponse(
+			oauth.NewError(oauth.InvalidToken, "the user of the token doesn't exist anymore"),
+			oauth.ScopeOpenID,
+		)
+	}
+
+	return oauth.JSONResponse(http.StatusOK, oauth.NewUserInfo(user, token.Scope))
+}
+
+func main() {
+	lambda.Start(HandleUserInfo)
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+)
+
+// AuthorizationRequest : an authorization request waiting for the user to
+// log in with an upstream provider
+type AuthorizationRequest struct {
+	ID          string    `json:"id"`
+	ClientID    string    `json:"client_id"`
+	RedirectURI string    `json:"redirect_uri"`
+	Scope       string    `json:"scope"`
+	State       string    `json:"state"`
+	Provider    string    `json:"provider"`
+	Created     time.Time `json:"created"`
+	ExpiresAt   time.Time `json:"expires_at"`
+
+	// RedirectURIGiven : the client sent redirect_uri, and must send it again
+	// with the code (RFC 6749 section 4.1.3)
+	RedirectURIGiven bool `json:"redirect_uri_given"`
+
+	// PKCE challenge (RFC 7636), carried over to the code
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+
+	// OpenID Connect nonce, echoed in the ID token
+	Nonce string `json:"nonce"`
+
+	// OpenID Connect prompt and max_age, deciding whether the GOAuth session
+	// can be used instead of a new login. MaxAge is -1 when not set.
+	Prompt string `json:"prompt"`
+	MaxAge int    `json:"max_age"`
+
+	// Set when the login approves a device authorization instead of
+	// redirecting to the client
+	DeviceUserCode string `json:"device_user_code"`
+
+	// Set once the user logged in, while he is asked for his consent
+	UserID   int       `json:"user_id"`
+	AuthTime time.Time `json:"auth_time"`
+	SID      string    `json:"sid"`
+}
+
+// AuthorizationCode : a short-lived code given to a client, to be exchanged
+// for an access token
+type AuthorizationCode struct {
+	Code        string    `json:"code"`
+	ClientID    string    `json:"client_id"`
+	UserID      int       `json:"user_id"`
+	RedirectURI string    `json:"redirect_uri"`
+	Scope       string    `json:"scope"`
+	Created     time.Time `json:"created"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Used        bool      `json:"used"`
+
+	RedirectURIGiven bool `json:"redirect_uri_given"`
+
+	// FamilyID : the family of the tokens issued for the code, set once used
+	FamilyID string `json:"family_id"`
+
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+
+	// How and when the user authenticated, for the ID token
+	Nonce    string    `json:"nonce"`
+	Provider string    `json:"provider"`
+	AuthTime time.Time `json:"auth_time"`
+	SID      string    `json:"sid"`
+}
+
+// Expired returns true if the request can't be completed anymore
+func (r *AuthorizationRequest) Expired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
+
+// Expired returns true if the code can't be exchanged anymore
+func (c *AuthorizationCode) Expired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+)
+
+// Grant types a client can be allowed to use
+const (
+	// AuthorizationCodeGrant : RFC 6749 section 4.1
+	AuthorizationCodeGrant = "authorization_code"
+
+	// RefreshTokenGrant : RFC 6749 section 6
+	RefreshTokenGrant = "refresh_token"
+
+	// ClientCredentialsGrant : RFC 6749 section 4.4
+	ClientCredentialsGrant = "client_credentials"
+
+	// DeviceCodeGrant : RFC 8628 section 3.4
+	DeviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// TokenExchangeGrant : RFC 8693 section 2.1
+	TokenExchangeGrant = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// Client : an application allowed to ask GOAuth for authorizations
+type Client struct {
+	ID           string    `json:"client_id"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Providers    []string  `json:"providers"`
+	GrantTypes   []string  `json:"grant_types"`
+	Created      time.Time `json:"created"`
+
+	// Public clients (SPAs, mobile apps) can't keep a secret, and must use PKCE
+	Public bool `json:"public"`
+
+	// MachineScopes can be granted to the client itself, without a user
+	MachineScopes []string `json:"machine_scopes"`
+
+	// FirstParty clients are our own applications, users aren't asked to
+	// consent to them
+	FirstParty bool `json:"first_party"`
+
+	// ExchangeAudiences are the services (client IDs) the client can get
+	// tokens for with a token exchange
+	ExchangeAudiences []string `json:"exchange_audiences"`
+
+	// UpstreamProviders are the providers whose tokens the client can get, to
+	// call their APIs on behalf of its users
+	UpstreamProviders []string `json:"upstream_providers"`
+
+	// Logout (OpenID Connect RP-Initiated and Back-Channel Logout): where the
+	// user can be sent after logging out, and where GOAuth posts the logout
+	// tokens when a session the client took part in ends
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris"`
+	BackchannelLogoutURI   string   `json:"backchannel_logout_uri"`
+
+	// Token lifetimes in seconds, the server defaults are used when 0
+	AccessTokenLifetime  int `json:"access_token_lifetime"`
+	RefreshTokenLifetime int `json:"refresh_token_lifetime"`
+}
+
+// SetSecret replaces the client secret. Only its hash is kept.
+func (c *Client) SetSecret(secret string) {
+	c.SecretHash = HashSecret(secret)
+}
+
+// CheckSecret returns true if the secret is the one of the client
+func (c *Client) CheckSecret(secret string) bool {
+	return c.SecretHash != "" && SecretMatches(secret, c.SecretHash)
+}
+
+// HasRedirectURI returns true if the URI has been registered for this client
+func (c *Client) HasRedirectURI(uri string) bool {
+	return contains(c.RedirectURIs, uri)
+}
+
+// HasPostLogoutRedirectURI returns true if the user can be sent to the URI
+// after logging out
+func (c *Client) HasPostLogoutRedirectURI(uri string) bool {
+	return contains(c.PostLogoutRedirectURIs, uri)
+}
+
+// AllowsScopes returns true if every scope can be requested by this client
+func (c *Client) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsMachineScopes returns true if every scope can be granted to the
+// client acting on its own behalf
+func (c *Client) AllowsMachineScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !contains(c.MachineScopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsAudience returns true if the client can exchange tokens for the service
+func (c *Client) AllowsAudience(audience string) bool {
+	return contains(c.ExchangeAudiences, audience)
+}
+
+// AllowsUpstream returns true if the client can get the user tokens of the
+// provider
+func (c *Client) AllowsUpstream(provider string) bool {
+	return contains(c.UpstreamProviders, provider)
+}
+
+// AllowsProvider returns true if the client users can log in with this provider
+func (c *Client) AllowsProvider(provider string) bool {
+	return contains(c.Providers, provider)
+}
+
+// AllowsGrantType returns true if the client can use this grant type
+func (c *Client) AllowsGrantType(grantType string) bool {
+	return contains(c.GrantTypes, grantType)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
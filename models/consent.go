@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// Consent : the scopes a user agreed to give a client
+type Consent struct {
+	UserID   int       `json:"user_id"`
+	ClientID string    `json:"client_id"`
+	Scope    string    `json:"scope"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
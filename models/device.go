@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// Device authorization statuses
+const (
+	// DevicePending : waiting for the user to enter the code
+	DevicePending = "pending"
+
+	// DeviceApproved : the user logged in, the device can get its tokens
+	DeviceApproved = "approved"
+
+	// DeviceDenied : the user refused the authorization
+	DeviceDenied = "denied"
+
+	// DeviceUsed : the device got its tokens
+	DeviceUsed = "used"
+)
+
+// DeviceAuthorization : an authorization requested by a device that can't
+// receive a redirection, like a CLI (RFC 8628)
+type DeviceAuthorization struct {
+	DeviceCode string    `json:"-"`
+	UserCode   string    `json:"user_code"`
+	ClientID   string    `json:"client_id"`
+	Scope      string    `json:"scope"`
+	Status     string    `json:"status"`
+	Created    time.Time `json:"created"`
+	ExpiresAt  time.Time `json:"expires_at"`
+
+	// Set when the user approves the authorization
+	UserID   int       `json:"user_id"`
+	Provider string    `json:"provider"`
+	AuthTime time.Time `json:"auth_time"`
+
+	// Polling interval in seconds, increased when the device polls too fast
+	Interval   int       `json:"interval"`
+	LastPolled time.Time `json:"last_polled"`
+}
+
+// Expired returns true if the device can't get tokens anymore
+func (d *DeviceAuthorization) Expired() bool {
+	return time.Now().After(d.ExpiresAt)
+}
@@ -0,0 +1,56 @@
+package models
+
+// FitBitProfile : the profile of a FitBit user, as documented for
+// https://api.fitbit.com/1/user/-/profile.json. The users table keeps the
+// FitBitUser fields, the identity of the user the whole profile.
+type FitBitProfile struct {
+	EncodedID   string `json:"encodedId"`
+	DisplayName string `json:"displayName"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	FullName    string `json:"fullName"`
+	AboutMe     string `json:"aboutMe"`
+
+	Avatar    string `json:"avatar"`
+	Avatar150 string `json:"avatar150"`
+	Avatar640 string `json:"avatar640"`
+
+	Age         int    `json:"age"`
+	DateOfBirth string `json:"dateOfBirth"`
+	Gender      string `json:"gender"`
+	Country     string `json:"country"`
+	MemberSince string `json:"memberSince"`
+
+	Locale              string `json:"locale"`
+	Timezone            string `json:"timezone"`
+	OffsetFromUTCMillis int64  `json:"offsetFromUTCMillis"`
+	StartDayOfWeek      string `json:"startDayOfWeek"`
+
+	Height              float64 `json:"height"`
+	HeightUnit          string  `json:"heightUnit"`
+	Weight              float64 `json:"weight"`
+	WeightUnit          string  `json:"weightUnit"`
+	DistanceUnit        string  `json:"distanceUnit"`
+	GlucoseUnit         string  `json:"glucoseUnit"`
+	WaterUnit           string  `json:"waterUnit"`
+	StrideLengthRunning float64 `json:"strideLengthRunning"`
+	StrideLengthWalking float64 `json:"strideLengthWalking"`
+
+	AverageDailySteps int  `json:"averageDailySteps"`
+	IsChild           bool `json:"isChild"`
+}
+
+// FitBitUser returns the fields of the profile stored on the user
+func (p *FitBitProfile) FitBitUser() *FitBitUser {
+	avatar := p.Avatar150
+	if avatar == "" {
+		avatar = p.Avatar
+	}
+
+	return &FitBitUser{
+		Age:       p.Age,
+		Avatar:    avatar,
+		FullName:  p.FullName,
+		EncodedID: p.EncodedID,
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Identity : a provider account linked to a user, who can log in with any of
+// his identities
+type Identity struct {
+	ID       int    `json:"id"`
+	UserID   int    `json:"user_id"`
+	Provider string `json:"provider"`
+
+	// Subject : the provider's unique identifier of the account
+	Subject string `json:"subject"`
+
+	// Profile : the account as last fetched from the provider
+	Profile json.RawMessage `json:"profile"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// LinkRequest : a logged in user linking another provider account, waiting
+// for the provider login
+type LinkRequest struct {
+	ID        string    `json:"id"`
+	UserID    int       `json:"user_id"`
+	SID       string    `json:"sid"`
+	Provider  string    `json:"provider"`
+	Created   time.Time `json:"created"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired returns true if the link can't be completed anymore
+func (r *LinkRequest) Expired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
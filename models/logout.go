@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// LogoutDelivery : a back-channel logout notification to send to a client,
+// retried until it is received
+type LogoutDelivery struct {
+	ID          int       `json:"id"`
+	ClientID    string    `json:"client_id"`
+	UserID      int       `json:"user_id"`
+	SID         string    `json:"sid"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	Created     time.Time `json:"created"`
+}
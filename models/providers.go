@@ -7,3 +7,6 @@ const (
 	// FitBitProvider : string representation
 	FitBitProvider = "fitbit"
 )
+
+// Providers : every upstream provider users can log in with
+var Providers = []string{GithubProvider, FitBitProvider}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// HashSecret returns the hash under which a secret (client secret, code,
+// token, ...) is stored, so a leak of the database doesn't leak credentials.
+// Secrets are generated randomly with enough entropy for a plain SHA-256.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// SecretMatches compares a secret to a stored hash in constant time
+func SecretMatches(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(hash)) == 1
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Session : a user logged in to GOAuth itself, identified by a cookie so that
+// the next applications he uses don't send him through the provider again.
+// The secret ID is only known by the cookie, the SID identifies the session
+// in ID and logout tokens.
+type Session struct {
+	ID        string    `json:"-"`
+	SID       string    `json:"sid"`
+	UserID    int       `json:"user_id"`
+	Provider  string    `json:"provider"`
+	AuthTime  time.Time `json:"auth_time"`
+	Created   time.Time `json:"created"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Active returns true if the session can still be used
+func (s *Session) Active() bool {
+	return time.Now().Before(s.ExpiresAt)
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Signing key statuses, in the order of their lifecycle
+const (
+	// KeyPending : published in the JWKS, but not used yet, so the clients
+	// caching the JWKS know it before the first token it signs
+	KeyPending = "pending"
+
+	// KeyActive : the key tokens are currently signed with
+	KeyActive = "active"
+
+	// KeyRetiring : not used anymore, but still published until the tokens it
+	// signed expire
+	KeyRetiring = "retiring"
+
+	// KeyRetired : not published anymore
+	KeyRetired = "retired"
+)
+
+// SigningKey : a key GOAuth signs its tokens with
+type SigningKey struct {
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Status    string `json:"status"`
+
+	// PublicKey is the JWK of the key, PrivateKey the PKCS#8 PEM encrypted
+	// with the master key
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"-"`
+
+	Created     time.Time `json:"created"`
+	ActivatedAt time.Time `json:"activated_at"`
+	RetireAt    time.Time `json:"retire_at"`
+}
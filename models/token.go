@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// AccessToken : an opaque token issued by GOAuth to a client, on behalf of a
+// user or of the client itself (UserID 0)
+type AccessToken struct {
+	Token     string    `json:"-"`
+	ClientID  string    `json:"client_id"`
+	UserID    int       `json:"user_id"`
+	Scope     string    `json:"scope"`
+	FamilyID  string    `json:"family_id"`
+	Created   time.Time `json:"created"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Revoked   bool      `json:"revoked"`
+
+	// Set on tokens issued by a token exchange: the service the token is
+	// restricted to, and the parties acting on behalf of the subject
+	Audience string `json:"audience"`
+	Actor    *Actor `json:"act"`
+}
+
+// Actor : a party acting on behalf of the subject of a token. Act holds the
+// previous actor of the delegation chain (RFC 8693 section 4.1).
+type Actor struct {
+	Subject string `json:"sub"`
+	Act     *Actor `json:"act,omitempty"`
+}
+
+// RefreshToken : a one-time-use token to get a new access token. Each use
+// rotates it; every token issued from the same authorization shares a family.
+type RefreshToken struct {
+	Token     string    `json:"-"`
+	FamilyID  string    `json:"family_id"`
+	ClientID  string    `json:"client_id"`
+	UserID    int       `json:"user_id"`
+	Scope     string    `json:"scope"`
+	Created   time.Time `json:"created"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Used      bool      `json:"used"`
+	Revoked   bool      `json:"revoked"`
+}
+
+// Active returns true if the token can still be used
+func (t *AccessToken) Active() bool {
+	return !t.Revoked && time.Now().Before(t.ExpiresAt)
+}
+
+// Subject returns the user the token was issued for, or the client when it
+// was issued without a user
+func (t *AccessToken) Subject() string {
+	if t.UserID == 0 {
+		return t.ClientID
+	}
+	return strconv.Itoa(t.UserID)
+}
+
+// Expired returns true if the token can't be used anymore
+func (t *RefreshToken) Expired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
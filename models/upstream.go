@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// UpstreamToken : the tokens a provider issued for a user, kept so that our
+// applications can call the provider APIs on his behalf. The tokens are
+// stored encrypted with the master key.
+type UpstreamToken struct {
+	UserID       int       `json:"user_id"`
+	Provider     string    `json:"provider"`
+	AccessToken  string    `json:"-"`
+	RefreshToken string    `json:"-"`
+	TokenType    string    `json:"token_type"`
+	Scope        string    `json:"scope"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+
+	// ExpiresAt is zero when the access token doesn't expire
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ExpiresWithin returns true if the access token expires in less than d
+func (t *UpstreamToken) ExpiresWithin(d time.Duration) bool {
+	return !t.ExpiresAt.IsZero() && time.Now().Add(d).After(t.ExpiresAt)
+}
@@ -1,25 +1,35 @@
 package models
 
-import (
-	"fmt"
-	"time"
-)
+import "time"
 
 // User : an application user
 type User struct {
 	ID        int       `json:"id"`
 	LastLogin time.Time `json:"last_login"`
 	Created   time.Time `json:"created"`
-	Provider  string    `json:"provider"`
+
+	// Provider : the provider the user first logged in with. He can link
+	// more, see Identity.
+	Provider string `json:"provider"`
+
 	*GHUser
 	*FitBitUser
 }
 
 // GHUser : a GitHub user
 type GHUser struct {
-	Bio      string `json:"bio"`
-	Blog     string `json:"blog"`
-	Email    string `json:"email"`
+	// GitHubID and NodeID identify the account, its login can be renamed and
+	// taken by someone else
+	GitHubID int64  `json:"id" db:"github_id"`
+	NodeID   string `json:"node_id" db:"github_node_id"`
+
+	Bio   string `json:"bio"`
+	Blog  string `json:"blog"`
+	Email string `json:"email"`
+
+	// EmailVerified : the email is the primary address GitHub verified
+	EmailVerified bool `json:"email_verified" db:"email_verified"`
+
 	Image    string `json:"avatar_url" db:"image"`
 	Location string `json:"location"`
 	Login    string `json:"login"`
@@ -46,30 +56,6 @@ func (u *User) RemoveNils() {
 	}
 }
 
-// GetUniqueIdentifier returns the unique of the user no matter the provider (email / ID / login / ...)
-func (u *User) GetUniqueIdentifier() (string, error) {
-	switch {
-	case u.Provider == GithubProvider:
-		return u.GHUser.Email, nil
-	case u.Provider == FitBitProvider:
-		return u.FitBitUser.EncodedID, nil
-	default:
-		return "", fmt.Errorf("No unique identifier for user %d / provider %s", u.ID, u.Provider)
-	}
-}
-
-// GetUniqueIdentifierName returns the column name for the unique identifier
-func (u *User) GetUniqueIdentifierName() (string, error) {
-	switch {
-	case u.Provider == GithubProvider:
-		return "login", nil
-	case u.Provider == FitBitProvider:
-		return "fitbit_id", nil
-	default:
-		return "", fmt.Errorf("No unique identifier provider %s", u.Provider)
-	}
-}
-
 // GetImage returns the image of the user no matter the provider
 func (u *User) GetImage() string {
 	switch {
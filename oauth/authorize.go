@@ -0,0 +1,270 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// Values of the OpenID Connect prompt parameter
+const (
+	// PromptNone : fail instead of asking the user to log in or to consent
+	PromptNone = "none"
+
+	// PromptLogin : log in with the provider again, even with a GOAuth session
+	PromptLogin = "login"
+)
+
+const (
+	// RequestLifetime : time given to the user to log in with the provider
+	RequestLifetime = 10 * time.Minute
+
+	// CodeLifetime : time given to the client to exchange its code
+	CodeLifetime = 5 * time.Minute
+)
+
+// ResolveRedirectURI returns the redirection URI to use for a client. It must
+// have been registered, and can only be omitted if the client has a single one.
+func ResolveRedirectURI(client *models.Client, uri string) (string, error) {
+	if uri == "" {
+		if len(client.RedirectURIs) != 1 {
+			return "", errors.New("redirect_uri is required")
+		}
+		return client.RedirectURIs[0], nil
+	}
+
+	if !client.HasRedirectURI(uri) {
+		return "", fmt.Errorf("redirect_uri %s is not registered for client %s", uri, client.ID)
+	}
+
+	return uri, nil
+}
+
+// NewAuthorizationRequest validates the parameters of an authorization request
+// (RFC 6749 section 4.1.1). The redirection URI must have been resolved first.
+// When no scope is requested, every scope allowed for the client is.
+func NewAuthorizationRequest(client *models.Client, redirectURI string, params map[string]string) (*models.AuthorizationRequest, *Error) {
+	if params["response_type"] != "code" {
+		return nil, NewError(UnsupportedResponseType, "only the code response type is supported")
+	}
+	if !client.AllowsGrantType(models.AuthorizationCodeGrant) {
+		return nil, NewError(UnauthorizedClient, "the client can't use the authorization code grant")
+	}
+	if provider := params["provider"]; provider != "" && !client.AllowsProvider(provider) {
+		return nil, NewError(InvalidRequest, "the provider is not allowed for this client")
+	}
+
+	scopes := ParseScope(params["scope"])
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, NewError(InvalidScope, "the requested scope is not allowed for this client")
+	}
+	if e := checkUserScopes(scopes); e != nil {
+		return nil, e
+	}
+
+	method, e := checkCodeChallenge(params["code_challenge"], params["code_challenge_method"], client.Public)
+	if e != nil {
+		return nil, e
+	}
+
+	prompt := params["prompt"]
+	if prompt != "" && prompt != PromptNone && prompt != PromptLogin {
+		return nil, NewError(InvalidRequest, "only the none and login prompts are supported")
+	}
+
+	maxAge := -1
+	if value := params["max_age"]; value != "" {
+		var err error
+		if maxAge, err = strconv.Atoi(value); err != nil || maxAge < 0 {
+			return nil, NewError(InvalidRequest, "max_age must be a number of seconds")
+		}
+	}
+
+	id, err := RandomString(32)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't generate the request ID")
+	}
+
+	return &models.AuthorizationRequest{
+		ID:          id,
+		ClientID:    client.ID,
+		RedirectURI: redirectURI,
+		Scope:       FormatScope(scopes),
+		State:       params["state"],
+		Provider:    params["provider"],
+		ExpiresAt:   time.Now().Add(RequestLifetime),
+
+		RedirectURIGiven:    params["redirect_uri"] != "",
+		CodeChallenge:       params["code_challenge"],
+		CodeChallengeMethod: method,
+		Nonce:               params["nonce"],
+		Prompt:              prompt,
+		MaxAge:              maxAge,
+	}, nil
+}
+
+// CanUseSession returns true if the user's GOAuth session can complete the
+// request without a new provider login: prompt=login and max_age (OpenID
+// Connect Core section 3.1.2.1) require a fresh one, and the session provider
+// must be allowed for the client
+func CanUseSession(client *models.Client, request *models.AuthorizationRequest, session *models.Session) bool {
+	switch {
+	case session == nil || !session.Active():
+		return false
+	case request.Prompt == PromptLogin:
+		return false
+	case request.MaxAge >= 0 && time.Since(session.AuthTime) > time.Duration(request.MaxAge)*time.Second:
+		return false
+	case request.Provider != "" && request.Provider != session.Provider:
+		return false
+	}
+	return client.AllowsProvider(session.Provider)
+}
+
+// CompleteAuthorization is called once the user is logged in, with a new or
+// an existing GOAuth session, and returns the URI to redirect him to: the
+// consent page when he hasn't consented to the requested scope yet, and the
+// client with an authorization code otherwise. Requests started from the
+// device page always go through the consent page, which approves the device
+// authorization and sends the user back to the device page.
+func CompleteAuthorization(db *database.Client, requestID string, session *models.Session) (string, error) {
+	request, err := db.GetAuthorizationRequest(requestID)
+	if err != nil {
+		return "", fmt.Errorf("unknown authorization request: %v", err)
+	}
+	request.Provider = session.Provider
+	request.SID = session.SID
+
+	client, err := db.GetClient(request.ClientID)
+	if err != nil {
+		return "", fmt.Errorf("unknown client: %v", err)
+	}
+
+	consented, err := hasConsent(db, client, session.UserID, request.Scope)
+	if err != nil {
+		return "", err
+	}
+	// the user always confirms a device authorization, whoever the client is:
+	// the code may have been sent by someone holding the device (RFC 8628
+	// section 5.4)
+	confirmed := consented && request.DeviceUserCode == ""
+	if !confirmed && !request.Expired() && request.Prompt != PromptNone {
+		if err = db.SetAuthorizationRequestUser(request.ID, session); err != nil {
+			return "", err
+		}
+		return ConsentURL(request.ID)
+	}
+
+	// a request can only be completed once
+	if err = db.DeleteAuthorizationRequest(request.ID); err != nil {
+		return "", err
+	}
+
+	if !consented && !request.Expired() {
+		return denyAuthorization(request, NewError(ConsentRequired, "the user must consent to the request"))
+	}
+
+	return grantAuthorization(db, request, session.UserID, session.AuthTime)
+}
+
+// AbortAuthorization completes a request the user couldn't log in for, and
+// returns the URI telling the client, or the user on the device page
+func AbortAuthorization(db *database.Client, requestID string, e *Error) (string, error) {
+	request, err := db.GetAuthorizationRequest(requestID)
+	if err != nil {
+		return "", fmt.Errorf("unknown authorization request: %v", err)
+	}
+
+	if err = db.DeleteAuthorizationRequest(request.ID); err != nil {
+		return "", err
+	}
+
+	return denyAuthorization(request, e)
+}
+
+// CheckLoginState checks that a provider called back for a login GOAuth
+// started: the state must be a pending authorization request, sent to that
+// provider. It is checked before trusting anything the provider sent.
+func CheckLoginState(db *database.Client, state, provider string) error {
+	request, err := db.GetAuthorizationRequest(state)
+	if err != nil {
+		return errors.New("unknown state: the login wasn't started by GOAuth")
+	}
+
+	switch {
+	case request.Provider != provider:
+		return fmt.Errorf("the login was started with %s, not %s", request.Provider, provider)
+	case request.UserID != 0:
+		return errors.New("the login was already completed")
+	case request.Expired():
+		return errors.New("the login has expired, start again from the application")
+	}
+	return nil
+}
+
+// grantAuthorization issues the authorization code of a completed request,
+// or approves its device authorization
+func grantAuthorization(db *database.Client, request *models.AuthorizationRequest, userID int, authTime time.Time) (string, error) {
+	if request.Expired() {
+		return denyAuthorization(request, NewError(AccessDenied, "the authorization request expired"))
+	}
+
+	// the client will be notified when the session ends
+	if request.SID != "" {
+		if err := db.AddSessionClient(request.SID, request.ClientID); err != nil {
+			return "", err
+		}
+	}
+
+	if request.DeviceUserCode != "" {
+		return approveDevice(db, request, userID, authTime)
+	}
+
+	code, err := RandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = db.CreateAuthorizationCode(&models.AuthorizationCode{
+		Code:        code,
+		ClientID:    request.ClientID,
+		UserID:      userID,
+		RedirectURI: request.RedirectURI,
+		Scope:       request.Scope,
+		ExpiresAt:   time.Now().Add(CodeLifetime),
+
+		RedirectURIGiven:    request.RedirectURIGiven,
+		CodeChallenge:       request.CodeChallenge,
+		CodeChallengeMethod: request.CodeChallengeMethod,
+		Nonce:               request.Nonce,
+		Provider:            request.Provider,
+		AuthTime:            authTime,
+		SID:                 request.SID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return RedirectURL(request.RedirectURI, url.Values{
+		"code":  {code},
+		"state": {request.State},
+	})
+}
+
+// denyAuthorization returns the URI telling the client, or the user on the
+// device page, that the request wasn't granted
+func denyAuthorization(request *models.AuthorizationRequest, e *Error) (string, error) {
+	if request.DeviceUserCode != "" {
+		return DevicePageURL(url.Values{"error": {e.Description}})
+	}
+
+	return ErrorRedirectURL(request.RedirectURI, request.State, e)
+}
@@ -0,0 +1,103 @@
+package oauth
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+var testClient = &models.Client{
+	ID:           "app",
+	RedirectURIs: []string{"https://app.example.com/callback"},
+	Scopes:       []string{"openid", "profile"},
+	Providers:    []string{models.GithubProvider},
+	GrantTypes:   []string{models.AuthorizationCodeGrant},
+}
+
+func TestResolvingRedirectURI(t *testing.T) {
+	uri, err := ResolveRedirectURI(testClient, "")
+	if err != nil || uri != testClient.RedirectURIs[0] {
+		t.Errorf("the only registered URI should be used by default, got %s (%v)", uri, err)
+	}
+
+	if _, err = ResolveRedirectURI(testClient, "https://evil.example.com/callback"); err == nil {
+		t.Error("an unregistered redirect_uri should be rejected")
+	}
+}
+
+func TestValidatingAuthorizationRequest(t *testing.T) {
+	uri := testClient.RedirectURIs[0]
+
+	_, e := NewAuthorizationRequest(testClient, uri, map[string]string{"response_type": "token"})
+	if e == nil || e.Code != UnsupportedResponseType {
+		t.Errorf("expected %s, got %v", UnsupportedResponseType, e)
+	}
+
+	_, e = NewAuthorizationRequest(testClient, uri, map[string]string{
+		"response_type": "code",
+		"scope":         "openid admin",
+	})
+	if e == nil || e.Code != InvalidScope {
+		t.Errorf("expected %s, got %v", InvalidScope, e)
+	}
+
+	adminClient := *testClient
+	adminClient.Scopes = []string{"openid", "admin"}
+	_, e = NewAuthorizationRequest(&adminClient, uri, map[string]string{
+		"response_type": "code",
+		"scope":         "openid admin",
+	})
+	if e == nil || e.Code != InvalidScope {
+		t.Errorf("users can't delegate admin, expected %s, got %v", InvalidScope, e)
+	}
+
+	_, e = NewAuthorizationRequest(testClient, uri, map[string]string{
+		"response_type": "code",
+		"provider":      models.FitBitProvider,
+	})
+	if e == nil || e.Code != InvalidRequest {
+		t.Errorf("expected %s, got %v", InvalidRequest, e)
+	}
+
+	r, e := NewAuthorizationRequest(testClient, uri, map[string]string{
+		"response_type": "code",
+		"state":         "xyz",
+	})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if r.Scope != "openid profile" || r.State != "xyz" || r.ID == "" {
+		t.Errorf("unexpected request: %+v", r)
+	}
+	if r.RedirectURIGiven {
+		t.Error("the client didn't send redirect_uri, it doesn't have to send it with the code")
+	}
+
+	r, _ = NewAuthorizationRequest(testClient, uri, map[string]string{
+		"response_type": "code",
+		"redirect_uri":  uri,
+	})
+	if !r.RedirectURIGiven {
+		t.Error("the client sent redirect_uri, it must send it with the code")
+	}
+}
+
+func TestRedirectURLKeepsQuery(t *testing.T) {
+	location, err := RedirectURL("https://app.example.com/cb?tab=1", url.Values{
+		"code":  {"abc"},
+		"state": {""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(location)
+	q := u.Query()
+	if q.Get("tab") != "1" || q.Get("code") != "abc" {
+		t.Errorf("unexpected redirection: %s", location)
+	}
+	if _, ok := q["state"]; ok {
+		t.Error("empty parameters should be omitted")
+	}
+}
@@ -0,0 +1,89 @@
+package oauth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// Error codes defined by RFC 6750 section 3.1
+const (
+	InvalidToken      = "invalid_token"
+	InsufficientScope = "insufficient_scope"
+)
+
+// ErrNoToken : the request has no authentication information, the challenge
+// must not carry an error code (RFC 6750 section 3.1)
+var ErrNoToken = &Error{Description: "a bearer token is required"}
+
+// AuthenticateBearer returns the active access token sent in the
+// Authorization header (RFC 6750 section 2.1)
+func AuthenticateBearer(db *database.Client, request *events.APIGatewayProxyRequest) (*models.AccessToken, *Error) {
+	header := Header(request, "Authorization")
+	if header == "" {
+		return nil, ErrNoToken
+	}
+
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return nil, NewError(InvalidRequest, "the Authorization header must use the Bearer scheme")
+	}
+
+	token, err := db.GetAccessToken(strings.TrimSpace(header[7:]))
+	if err != nil || !token.Active() {
+		return nil, NewError(InvalidToken, "the access token is invalid, expired or revoked")
+	}
+
+	return token, nil
+}
+
+// RequireScope returns an insufficient_scope error if the token wasn't granted
+// every scope
+func RequireScope(token *models.AccessToken, scope string) *Error {
+	if !ScopeIncludes(token.Scope, scope) {
+		return NewError(InsufficientScope, "the access token requires the "+scope+" scope")
+	}
+	return nil
+}
+
+// RequireAdmin returns an insufficient_scope error unless the token was issued
+// to a client on its own behalf with the admin scope. User tokens never carry
+// it, even when issued before it was restricted.
+func RequireAdmin(token *models.AccessToken) *Error {
+	if token.UserID != 0 || !ScopeIncludes(token.Scope, ScopeAdmin) {
+		return NewError(InsufficientScope, "the access token requires the "+ScopeAdmin+" scope, granted to clients only")
+	}
+	return nil
+}
+
+// BearerErrorResponse returns the error along with its WWW-Authenticate
+// challenge (RFC 6750 section 3). scope is the scope required by the resource.
+func BearerErrorResponse(e *Error, scope string) (events.APIGatewayProxyResponse, error) {
+	challenge := `Bearer realm="goauth"`
+	if e.Code != "" {
+		challenge += fmt.Sprintf(`, error="%s", error_description="%s"`, e.Code, e.Description)
+	}
+	if e.Code == InsufficientScope && scope != "" {
+		challenge += fmt.Sprintf(`, scope="%s"`, scope)
+	}
+
+	var status int
+	switch e.Code {
+	case "", InvalidToken:
+		status = http.StatusUnauthorized
+	case InsufficientScope:
+		status = http.StatusForbidden
+	default:
+		status = e.StatusCode()
+	}
+
+	resp, err := JSONResponse(status, e)
+	if e.Code == "" {
+		resp.Body = ""
+	}
+	resp.Headers["WWW-Authenticate"] = challenge
+	return resp, err
+}
@@ -0,0 +1,78 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// AuthenticateClient authenticates a client with client_secret_basic (the
+// Authorization header) or client_secret_post (the form parameters), as
+// described in RFC 6749 section 2.3.1. Public clients only identify themselves
+// with their client_id, their codes being protected by PKCE instead.
+func AuthenticateClient(db *database.Client, request *events.APIGatewayProxyRequest, form url.Values) (*models.Client, *Error) {
+	id, secret, basic, err := basicCredentials(request)
+	if err != nil {
+		return nil, NewError(InvalidClient, "malformed Authorization header")
+	}
+
+	if basic {
+		if form.Get("client_secret") != "" {
+			return nil, NewError(InvalidRequest, "only one client authentication method can be used")
+		}
+	} else {
+		id = form.Get("client_id")
+		secret = form.Get("client_secret")
+	}
+
+	if id == "" {
+		return nil, NewError(InvalidClient, "client authentication is required")
+	}
+
+	client, err := db.GetClient(id)
+	if err != nil {
+		return nil, NewError(InvalidClient, "client authentication failed")
+	}
+
+	if client.Public {
+		if secret != "" {
+			return nil, NewError(InvalidClient, "public clients don't have a secret")
+		}
+		return client, nil
+	}
+
+	if !client.CheckSecret(secret) {
+		return nil, NewError(InvalidClient, "client authentication failed")
+	}
+
+	return client, nil
+}
+
+// Client credentials are form-urlencoded before being put in the header
+func basicCredentials(request *events.APIGatewayProxyRequest) (string, string, bool, error) {
+	header := Header(request, "Authorization")
+	if len(header) < 6 || !strings.EqualFold(header[:6], "Basic ") {
+		return "", "", false, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(header[6:]))
+	if err != nil {
+		return "", "", true, err
+	}
+
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", "", true, NewError(InvalidClient, "malformed credentials")
+	}
+
+	id, err := url.QueryUnescape(parts[0])
+	if err != nil {
+		return "", "", true, err
+	}
+	secret, err := url.QueryUnescape(parts[1])
+	return id, secret, true, err
+}
@@ -0,0 +1,43 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestParsingBasicCredentials(t *testing.T) {
+	// RFC 6749 section 2.3.1: both parts are form-urlencoded first
+	credentials := base64.StdEncoding.EncodeToString([]byte("my%3Aapp:s3cr%2Bt"))
+	request := &events.APIGatewayProxyRequest{
+		Headers: map[string]string{"authorization": "Basic " + credentials},
+	}
+
+	id, secret, basic, err := basicCredentials(request)
+	if err != nil || !basic {
+		t.Fatalf("the credentials should be parsed: %v", err)
+	}
+	if id != "my:app" || secret != "s3cr+t" {
+		t.Errorf("unexpected credentials %s / %s", id, secret)
+	}
+
+	request.Headers["authorization"] = "Bearer abc"
+	if _, _, basic, _ = basicCredentials(request); basic {
+		t.Error("only the Basic scheme should be read")
+	}
+}
+
+func TestInvalidClientResponse(t *testing.T) {
+	resp, _ := ErrorResponse(NewError(InvalidClient, "client authentication failed"))
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+	if resp.Headers["WWW-Authenticate"] == "" {
+		t.Error("invalid_client responses should carry WWW-Authenticate")
+	}
+	if resp.Body != `{"error":"invalid_client","error_description":"client authentication failed"}` {
+		t.Errorf("unexpected body %s", resp.Body)
+	}
+}
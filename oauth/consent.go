@@ -0,0 +1,197 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/url"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// ScopeDescriptions : what the user agrees to share, shown on the consent page
+var ScopeDescriptions = map[string]string{
+	ScopeOpenID:  "Know who you are",
+	ScopeProfile: "See your name, picture and GitHub or FitBit profile",
+	ScopeEmail:   "See your email address",
+
+	UpstreamScope(models.GithubProvider): "Use your GitHub account on your behalf",
+	UpstreamScope(models.FitBitProvider): "Read your FitBit data on your behalf",
+}
+
+// ConsentPrompt : what the consent page asks the user
+type ConsentPrompt struct {
+	RequestID  string
+	ClientName string
+	Scopes     []ScopeDescription
+
+	// Sent back with the decision, proving it was made on this page
+	CSRFToken string
+
+	// Set when the request authorizes a device, whose screen shows the code
+	UserCode string
+}
+
+// ScopeDescription : a scope with its human readable description
+type ScopeDescription struct {
+	Scope       string
+	Description string
+}
+
+// ConsentURL returns the URL of the consent page of an authorization request
+func ConsentURL(requestID string) (string, error) {
+	iss, err := Issuer()
+	if err != nil {
+		return "", err
+	}
+
+	return iss + ConsentPath + "?" + url.Values{"request": {requestID}}.Encode(), nil
+}
+
+// hasConsent returns true if the user doesn't have to be asked before giving
+// the scope to the client: first-party clients don't ask
+func hasConsent(db *database.Client, client *models.Client, userID int, scope string) (bool, error) {
+	if client.FirstParty {
+		return true, nil
+	}
+
+	consent, err := db.GetConsent(userID, client.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return ScopeIncludes(consent.Scope, scope), nil
+}
+
+// GetConsentPrompt describes the request the user of the session is asked to
+// consent to
+func GetConsentPrompt(db *database.Client, requestID string, session *models.Session) (*ConsentPrompt, error) {
+	request, err := consentRequest(db, requestID, session)
+	if err != nil {
+		return nil, err
+	}
+
+	csrfToken, err := consentToken(request, session)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := db.GetClient(request.ClientID)
+	if err != nil {
+		return nil, errors.New("unknown client")
+	}
+
+	prompt := &ConsentPrompt{
+		RequestID:  request.ID,
+		ClientName: client.Name,
+		UserCode:   request.DeviceUserCode,
+		CSRFToken:  csrfToken,
+	}
+	if prompt.ClientName == "" {
+		prompt.ClientName = client.ID
+	}
+	for _, scope := range ParseScope(request.Scope) {
+		description, ok := ScopeDescriptions[scope]
+		if !ok {
+			description = scope
+		}
+		prompt.Scopes = append(prompt.Scopes, ScopeDescription{Scope: scope, Description: description})
+	}
+
+	return prompt, nil
+}
+
+// DecideConsent completes a request once the user of the session allowed or
+// denied it on the consent page, and returns the URI to redirect him to. An
+// allowed scope is added to the consent the user already gave the client.
+func DecideConsent(db *database.Client, requestID, csrfToken string, session *models.Session, allow bool) (string, error) {
+	request, err := consentRequest(db, requestID, session)
+	if err != nil {
+		return "", err
+	}
+
+	expected, err := consentToken(request, session)
+	if err != nil {
+		return "", err
+	}
+	if subtle.ConstantTimeCompare([]byte(csrfToken), []byte(expected)) != 1 {
+		return "", errors.New("the decision wasn't made on the consent page")
+	}
+
+	// a request can only be completed once
+	if err = db.DeleteAuthorizationRequest(request.ID); err != nil {
+		return "", err
+	}
+
+	if !allow {
+		if request.DeviceUserCode != "" {
+			db.DenyDeviceAuthorization(request.DeviceUserCode)
+		}
+		return denyAuthorization(request, NewError(AccessDenied, "the user denied the authorization"))
+	}
+
+	scope := request.Scope
+	consent, err := db.GetConsent(request.UserID, request.ClientID)
+	if err == nil {
+		scope = mergeScopes(consent.Scope, request.Scope)
+	} else if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	err = db.SaveConsent(&models.Consent{
+		UserID:   request.UserID,
+		ClientID: request.ClientID,
+		Scope:    scope,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return grantAuthorization(db, request, request.UserID, request.AuthTime)
+}
+
+// consentRequest returns a request waiting for the consent of the user who
+// logged in. The request ID is also the state sent to the provider, so it
+// isn't secret: only the session the user logged in with can decide.
+func consentRequest(db *database.Client, requestID string, session *models.Session) (*models.AuthorizationRequest, error) {
+	if session == nil {
+		return nil, errors.New("you must be logged in to GOAuth")
+	}
+
+	request, err := db.GetAuthorizationRequest(requestID)
+	if err != nil || request.UserID == 0 {
+		return nil, errors.New("unknown authorization request")
+	}
+	if request.SID != session.SID || request.UserID != session.UserID {
+		return nil, errors.New("the authorization request belongs to another session")
+	}
+
+	return request, nil
+}
+
+// consentToken returns the CSRF token of the consent page of a request, bound
+// to the session deciding it
+func consentToken(request *models.AuthorizationRequest, session *models.Session) (string, error) {
+	mac, err := keys.MAC([]byte("consent " + request.ID + " " + session.SID))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(mac), nil
+}
+
+// mergeScopes returns the scopes of both scope strings, without duplicates
+func mergeScopes(a, b string) string {
+	scopes := ParseScope(a)
+	for _, scope := range ParseScope(b) {
+		if !contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return FormatScope(scopes)
+}
@@ -0,0 +1,36 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestMergeScopes(t *testing.T) {
+	if merged := mergeScopes("openid profile", "profile email"); merged != "openid profile email" {
+		t.Errorf("unexpected merged scope %q", merged)
+	}
+	if merged := mergeScopes("", "openid"); merged != "openid" {
+		t.Errorf("unexpected merged scope %q", merged)
+	}
+}
+
+func TestConsentTokenIsBoundToSession(t *testing.T) {
+	os.Setenv("MASTER_KEY", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+
+	request := &models.AuthorizationRequest{ID: "request-id"}
+	token, err := consentToken(request, &models.Session{SID: "victim"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := consentToken(request, &models.Session{SID: "attacker"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token == other {
+		t.Error("another session shouldn't get the same consent token")
+	}
+}
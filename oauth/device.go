@@ -0,0 +1,284 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+const (
+	// DeviceCodeLifetime : time given to the user to enter the code
+	DeviceCodeLifetime = 10 * time.Minute
+
+	// DevicePollingInterval : minimum time between two polls of the device,
+	// increased each time it polls too fast
+	DevicePollingInterval = 5 * time.Second
+)
+
+// User codes avoid vowels, so they can't spell words, and characters that look
+// alike (RFC 8628 section 6.1)
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// DeviceAuthorizationResponse : device authorization endpoint response (RFC
+// 8628 section 3.2)
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// NewDeviceAuthorization starts a device authorization (RFC 8628 section 3.1)
+// for an authenticated client. When no scope is requested, every scope
+// allowed for the client is.
+func NewDeviceAuthorization(db *database.Client, client *models.Client, form url.Values) (*DeviceAuthorizationResponse, *Error) {
+	if !client.AllowsGrantType(models.DeviceCodeGrant) {
+		return nil, NewError(UnauthorizedClient, "the client can't use the device code grant")
+	}
+
+	scopes := ParseScope(form.Get("scope"))
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, NewError(InvalidScope, "the requested scope is not allowed for this client")
+	}
+	if e := checkUserScopes(scopes); e != nil {
+		return nil, e
+	}
+
+	iss, err := Issuer()
+	if err != nil {
+		return nil, NewError(ServerError, err.Error())
+	}
+
+	deviceCode, err := RandomString(32)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't create the device code")
+	}
+	userCode, err := NewUserCode()
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't create the user code")
+	}
+
+	err = db.CreateDeviceAuthorization(&models.DeviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientID:   client.ID,
+		Scope:      FormatScope(scopes),
+		Status:     models.DevicePending,
+		Interval:   int(DevicePollingInterval.Seconds()),
+		ExpiresAt:  time.Now().Add(DeviceCodeLifetime),
+	})
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't save the device authorization")
+	}
+
+	return &DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         iss + DevicePath,
+		VerificationURIComplete: iss + DevicePath + "?" + url.Values{"user_code": {userCode}}.Encode(),
+		ExpiresIn:               int(DeviceCodeLifetime.Seconds()),
+		Interval:                int(DevicePollingInterval.Seconds()),
+	}, nil
+}
+
+// NewUserCode returns a random code for the user to type, formatted as
+// XXXX-XXXX
+func NewUserCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, 0, 9)
+	for i, c := range b {
+		if i == 4 {
+			code = append(code, '-')
+		}
+		// 256 isn't a multiple of 20, but the bias is small enough for a code
+		// that only lives a few minutes
+		code = append(code, userCodeAlphabet[int(c)%len(userCodeAlphabet)])
+	}
+	return string(code), nil
+}
+
+// NormalizeUserCode formats a code typed by the user, who may have used lower
+// case or left out the dash
+func NormalizeUserCode(code string) string {
+	chars := []rune{}
+	for _, c := range strings.ToUpper(code) {
+		if strings.ContainsRune(userCodeAlphabet, c) {
+			chars = append(chars, c)
+		}
+	}
+	if len(chars) != 8 {
+		return string(chars)
+	}
+	return string(chars[:4]) + "-" + string(chars[4:])
+}
+
+// StartDeviceLogin creates the authorization request of the user who entered
+// a code, and returns where to send him: through the provider login, or to
+// the consent page when no provider is given and his GOAuth session can be
+// used
+func StartDeviceLogin(db *database.Client, userCode, provider string, session *models.Session) (string, error) {
+	device, err := db.GetDeviceAuthorizationByUserCode(NormalizeUserCode(userCode))
+	if err != nil {
+		return "", errors.New("unknown code")
+	}
+	if device.Status != models.DevicePending || device.Expired() {
+		return "", errors.New("the code has expired")
+	}
+
+	client, err := db.GetClient(device.ClientID)
+	if err != nil {
+		return "", errors.New("unknown client")
+	}
+
+	requestID, err := RandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	request := &models.AuthorizationRequest{
+		ID:             requestID,
+		ClientID:       device.ClientID,
+		Scope:          device.Scope,
+		Provider:       provider,
+		ExpiresAt:      device.ExpiresAt,
+		MaxAge:         -1,
+		DeviceUserCode: device.UserCode,
+	}
+
+	// single sign-on: the consent page still asks the user to confirm
+	if provider == "" {
+		if !CanUseSession(client, request, session) {
+			return "", errors.New("log in with a provider allowed for this application")
+		}
+		if err = db.CreateAuthorizationRequest(request); err != nil {
+			return "", err
+		}
+		return CompleteAuthorization(db, request.ID, session)
+	}
+
+	if !client.AllowsProvider(provider) {
+		return "", errors.New("the provider is not allowed for this application")
+	}
+
+	loginURL, err := providers.LoginURL(provider, requestID)
+	if err != nil {
+		return "", err
+	}
+
+	if err = db.CreateAuthorizationRequest(request); err != nil {
+		return "", err
+	}
+
+	return loginURL, nil
+}
+
+// approveDevice records the user who logged in on the device authorization,
+// and returns the device page URL to send him back to
+func approveDevice(db *database.Client, request *models.AuthorizationRequest, userID int, authTime time.Time) (string, error) {
+	err := db.ApproveDeviceAuthorization(request.DeviceUserCode, userID, request.Provider, authTime)
+	if err != nil {
+		return DevicePageURL(url.Values{"error": {"the code has expired or was already used"}})
+	}
+
+	return DevicePageURL(url.Values{"approved": {"true"}})
+}
+
+// DevicePageURL returns the URL of the device page with query parameters
+func DevicePageURL(params url.Values) (string, error) {
+	iss, err := Issuer()
+	if err != nil {
+		return "", err
+	}
+
+	return iss + DevicePath + "?" + params.Encode(), nil
+}
+
+// ExchangeDeviceCode handles the device_code grant (RFC 8628 section 3.4) for
+// an authenticated client polling until the user approves the authorization
+func ExchangeDeviceCode(db *database.Client, client *models.Client, form url.Values) (*TokenResponse, *Error) {
+	deviceCode := form.Get("device_code")
+	if deviceCode == "" {
+		return nil, NewError(InvalidRequest, "device_code is required")
+	}
+
+	device, err := db.GetDeviceAuthorization(deviceCode)
+	if err != nil || device.ClientID != client.ID {
+		return nil, NewError(InvalidGrant, "unknown device code")
+	}
+
+	switch {
+	case device.Expired():
+		return nil, NewError(ExpiredToken, "the device code has expired")
+	case device.Status == models.DeviceDenied:
+		return nil, NewError(AccessDenied, "the user denied the authorization")
+	case device.Status == models.DeviceUsed:
+		return nil, NewError(InvalidGrant, "the device code has already been used")
+	case device.Status == models.DevicePending:
+		return nil, poll(db, deviceCode, device)
+	}
+
+	used, err := db.UseDeviceAuthorization(deviceCode)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't use the device code")
+	}
+	if !used {
+		return nil, NewError(InvalidGrant, "the device code has already been used")
+	}
+
+	familyID, err := RandomString(16)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't create the token family")
+	}
+
+	idToken, e := signIDToken(db, device.Scope, &authentication{
+		ClientID: device.ClientID,
+		UserID:   device.UserID,
+		Provider: device.Provider,
+		AuthTime: device.AuthTime,
+	})
+	if e != nil {
+		return nil, e
+	}
+
+	resp, err := issueTokens(db, client, device.UserID, device.Scope, device.Scope, familyID)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't issue the tokens")
+	}
+	resp.IDToken = idToken
+
+	return resp, nil
+}
+
+// poll records a poll of a pending authorization, and slows the device down
+// when it polls before its interval elapsed (RFC 8628 section 3.5)
+func poll(db *database.Client, deviceCode string, device *models.DeviceAuthorization) *Error {
+	now := time.Now()
+	interval := time.Duration(device.Interval) * time.Second
+
+	e := NewError(AuthorizationPending, "the user hasn't approved the authorization yet")
+	if !device.LastPolled.IsZero() && now.Sub(device.LastPolled) < interval {
+		interval += DevicePollingInterval
+		e = NewError(SlowDown, "the device polls too fast")
+	}
+
+	if err := db.UpdateDevicePolling(deviceCode, now, int(interval.Seconds())); err != nil {
+		return NewError(ServerError, "couldn't record the poll")
+	}
+
+	return e
+}
@@ -0,0 +1,36 @@
+package oauth
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewUserCode(t *testing.T) {
+	format := regexp.MustCompile(`^[BCDFGHJKLMNPQRSTVWXZ]{4}-[BCDFGHJKLMNPQRSTVWXZ]{4}$`)
+	for i := 0; i < 100; i++ {
+		code, err := NewUserCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("unexpected user code %s", code)
+		}
+		if NormalizeUserCode(code) != code {
+			t.Fatalf("normalizing %s should leave it unchanged", code)
+		}
+	}
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	tests := map[string]string{
+		"wdjb-mjht":   "WDJB-MJHT",
+		"WDJBMJHT":    "WDJB-MJHT",
+		" wdjb mjht ": "WDJB-MJHT",
+		"WDJB":        "WDJB",
+	}
+	for input, expected := range tests {
+		if got := NormalizeUserCode(input); got != expected {
+			t.Errorf("NormalizeUserCode(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
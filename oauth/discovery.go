@@ -0,0 +1,119 @@
+package oauth
+
+import (
+	"sort"
+
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+// Paths of the GOAuth endpoints, relative to the issuer
+const (
+	AuthorizationPath = "/authorize"
+	TokenPath         = "/token"
+	UserInfoPath      = "/userinfo"
+	IntrospectionPath = "/introspect"
+	RevocationPath    = "/revoke"
+	JWKSPath          = "/.well-known/jwks.json"
+
+	DeviceAuthorizationPath = "/device_authorization"
+	DevicePath              = "/device"
+
+	ConsentPath    = "/consent"
+	EndSessionPath = "/end_session"
+)
+
+// Metadata : authorization server metadata (RFC 8414, OpenID Connect
+// Discovery section 3)
+type Metadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	EndSessionEndpoint                string   `json:"end_session_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	BackchannelLogoutSupported        bool     `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported bool     `json:"backchannel_logout_session_supported"`
+
+	// GOAuth extension: values of the provider parameter of /authorize
+	ProvidersSupported []string `json:"providers_supported"`
+}
+
+// NewMetadata describes the current configuration of the server, signing
+// with the published keys
+func NewMetadata(published *jwt.JWKSet) (*Metadata, error) {
+	iss, err := Issuer()
+	if err != nil {
+		return nil, err
+	}
+
+	algorithms := []string{}
+	for _, k := range published.Keys {
+		if !contains(algorithms, k.Alg) {
+			algorithms = append(algorithms, k.Alg)
+		}
+	}
+
+	grantTypes := []string{}
+	for grantType := range Grants {
+		grantTypes = append(grantTypes, grantType)
+	}
+	sort.Strings(grantTypes)
+
+	// the upstream scopes follow the registered providers
+	upstreamScopes := []string{}
+	for name := range providers.Providers {
+		upstreamScopes = append(upstreamScopes, UpstreamScope(name))
+	}
+	sort.Strings(upstreamScopes)
+	scopes := append([]string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopeAdmin}, upstreamScopes...)
+
+	challengeMethods := []string{PKCES256}
+	if allowPlainPKCE {
+		challengeMethods = append(challengeMethods, PKCEPlain)
+	}
+
+	return &Metadata{
+		Issuer:                            iss,
+		AuthorizationEndpoint:             iss + AuthorizationPath,
+		TokenEndpoint:                     iss + TokenPath,
+		UserInfoEndpoint:                  iss + UserInfoPath,
+		JWKSURI:                           iss + JWKSPath,
+		IntrospectionEndpoint:             iss + IntrospectionPath,
+		RevocationEndpoint:                iss + RevocationPath,
+		DeviceAuthorizationEndpoint:       iss + DeviceAuthorizationPath,
+		EndSessionEndpoint:                iss + EndSessionPath,
+		ScopesSupported:                   scopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               grantTypes,
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  algorithms,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     challengeMethods,
+		ClaimsSupported:                   userInfoClaims,
+		BackchannelLogoutSupported:        true,
+		BackchannelLogoutSessionSupported: true,
+		ProvidersSupported:                models.Providers,
+	}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
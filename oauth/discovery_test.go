@@ -0,0 +1,42 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestDescribingServer(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := jwt.NewJWK(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer = "https://auth.example.com"
+
+	metadata, err := NewMetadata(&jwt.JWKSet{Keys: []*jwt.JWK{jwk, jwk}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.TokenEndpoint != "https://auth.example.com/token" {
+		t.Errorf("unexpected token endpoint %s", metadata.TokenEndpoint)
+	}
+	if len(metadata.GrantTypesSupported) != len(Grants) {
+		t.Errorf("every grant of the token endpoint should be listed: %v", metadata.GrantTypesSupported)
+	}
+	if !contains(metadata.ScopesSupported, UpstreamScope(models.FitBitProvider)) || !contains(metadata.ScopesSupported, ScopeAdmin) {
+		t.Errorf("every scope the server accepts should be listed: %v", metadata.ScopesSupported)
+	}
+	algs := metadata.IDTokenSigningAlgValuesSupported
+	if len(algs) != 1 || algs[0] != jwt.ES256 {
+		t.Errorf("unexpected algorithms %v", algs)
+	}
+}
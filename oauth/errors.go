@@ -0,0 +1,70 @@
+package oauth
+
+import (
+	"net/http"
+)
+
+// Error codes defined by RFC 6749
+const (
+	InvalidRequest          = "invalid_request"
+	InvalidClient           = "invalid_client"
+	InvalidGrant            = "invalid_grant"
+	UnauthorizedClient      = "unauthorized_client"
+	UnsupportedGrantType    = "unsupported_grant_type"
+	AccessDenied            = "access_denied"
+	UnsupportedResponseType = "unsupported_response_type"
+	InvalidScope            = "invalid_scope"
+	ServerError             = "server_error"
+	TemporarilyUnavailable  = "temporarily_unavailable"
+)
+
+// Error codes defined by RFC 8628, returned while a device polls
+const (
+	AuthorizationPending = "authorization_pending"
+	SlowDown             = "slow_down"
+	ExpiredToken         = "expired_token"
+)
+
+// Error codes defined by OpenID Connect Core section 3.1.2.6, when prompt=none
+// was requested but the user would have to interact
+const (
+	LoginRequired   = "login_required"
+	ConsentRequired = "consent_required"
+)
+
+// InvalidTarget : error code defined by RFC 8693, for a requested audience the
+// client can't get a token for
+const InvalidTarget = "invalid_target"
+
+// Error : an OAuth2 error, as described in RFC 6749 sections 4.1.2.1 and 5.2
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// NewError returns an OAuth2 error with a human readable description
+func NewError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// StatusCode returns the HTTP status to use when the error is returned by the
+// token endpoint
+func (e *Error) StatusCode() int {
+	switch e.Code {
+	case InvalidClient:
+		return http.StatusUnauthorized
+	case ServerError:
+		return http.StatusInternalServerError
+	case TemporarilyUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
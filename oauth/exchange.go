@@ -0,0 +1,153 @@
+package oauth
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// AccessTokenType : the only token type GOAuth exchanges and issues (RFC 8693
+// section 3)
+const AccessTokenType = "urn:ietf:params:oauth:token-type:access_token"
+
+// ExchangeToken handles the token exchange grant (RFC 8693 section 2) for an
+// authenticated confidential client: a service calling another one on behalf
+// of a user swaps the user token for one restricted to the other service,
+// with a scope at most as wide. The client, preceded by the subject of its
+// actor token if it sent one, is added to the delegation chain of the subject
+// token.
+func ExchangeToken(db *database.Client, client *models.Client, form url.Values) (*TokenResponse, *Error) {
+	audience := form.Get("audience")
+	if e := checkExchangeAudience(client, audience); e != nil {
+		return nil, e
+	}
+	if t := form.Get("requested_token_type"); t != "" && t != AccessTokenType {
+		return nil, NewError(InvalidRequest, "only access tokens can be requested")
+	}
+
+	subject, e := exchangedToken(db, form.Get("subject_token"), form.Get("subject_token_type"), "subject")
+	if e != nil {
+		return nil, e
+	}
+	if e = checkExchangeSubject(client, subject); e != nil {
+		return nil, e
+	}
+
+	var actorToken *models.AccessToken
+	if form.Get("actor_token") != "" {
+		if actorToken, e = exchangedToken(db, form.Get("actor_token"), form.Get("actor_token_type"), "actor"); e != nil {
+			return nil, e
+		}
+	}
+	actor, e := exchangeActor(client, subject, actorToken)
+	if e != nil {
+		return nil, e
+	}
+
+	scope := subject.Scope
+	if requested := form.Get("scope"); requested != "" {
+		if !ScopeIncludes(scope, requested) {
+			return nil, NewError(InvalidScope, "the requested scope exceeds the subject token one")
+		}
+		scope = FormatScope(ParseScope(requested))
+	}
+
+	token, err := RandomString(32)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't create the token")
+	}
+
+	// the exchanged token can't outlive the subject token
+	expiresAt := time.Now().Add(lifetime(client.AccessTokenLifetime, AccessTokenLifetime))
+	if subject.ExpiresAt.Before(expiresAt) {
+		expiresAt = subject.ExpiresAt
+	}
+
+	err = db.CreateAccessToken(&models.AccessToken{
+		Token:     token,
+		ClientID:  client.ID,
+		UserID:    subject.UserID,
+		Scope:     scope,
+		FamilyID:  subject.FamilyID,
+		ExpiresAt: expiresAt,
+		Audience:  audience,
+		Actor:     actor,
+	})
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't issue the token")
+	}
+
+	return &TokenResponse{
+		AccessToken:     token,
+		TokenType:       "Bearer",
+		ExpiresIn:       int(time.Until(expiresAt).Seconds()),
+		Scope:           scope,
+		IssuedTokenType: AccessTokenType,
+	}, nil
+}
+
+// checkExchangeAudience checks that a confidential client can get tokens for
+// the audience
+func checkExchangeAudience(client *models.Client, audience string) *Error {
+	if client.Public {
+		return NewError(UnauthorizedClient, "public clients can't exchange tokens")
+	}
+	if audience == "" {
+		return NewError(InvalidRequest, "audience is required")
+	}
+	if !client.AllowsAudience(audience) {
+		return NewError(InvalidTarget, "the client can't get tokens for "+audience)
+	}
+	return nil
+}
+
+// checkExchangeSubject checks that the client can exchange the subject token
+func checkExchangeSubject(client *models.Client, subject *models.AccessToken) *Error {
+	if subject.UserID == 0 {
+		return NewError(InvalidRequest, "the subject token wasn't issued on behalf of a user")
+	}
+	// a token restricted to a service can only be exchanged by that service
+	if subject.Audience != "" && subject.Audience != client.ID {
+		return NewError(InvalidGrant, "the subject token wasn't issued for this client")
+	}
+	return nil
+}
+
+// exchangeActor returns the delegation chain of the exchanged token: the
+// client, preceded by the subject of its actor token (nil when it sent none),
+// on top of the chain of the subject token
+func exchangeActor(client *models.Client, subject, actorToken *models.AccessToken) (*models.Actor, *Error) {
+	actor := &models.Actor{Subject: client.ID, Act: subject.Actor}
+	if actorToken == nil {
+		return actor, nil
+	}
+
+	// the client can only present its own tokens as the actor
+	if actorToken.ClientID != client.ID {
+		return nil, NewError(InvalidGrant, "the actor token wasn't issued to this client")
+	}
+	if sub := actorToken.Subject(); sub != client.ID {
+		actor = &models.Actor{Subject: sub, Act: actor}
+	}
+	return actor, nil
+}
+
+// exchangedToken returns the active access token given as the subject or
+// actor of a token exchange
+func exchangedToken(db *database.Client, token, tokenType, name string) (*models.AccessToken, *Error) {
+	if token == "" {
+		return nil, NewError(InvalidRequest, name+"_token is required")
+	}
+	if tokenType != AccessTokenType {
+		return nil, NewError(InvalidRequest, name+"_token_type must be "+AccessTokenType)
+	}
+
+	t, err := db.GetAccessToken(token)
+	if err != nil || !t.Active() {
+		return nil, NewError(InvalidGrant, "the "+name+" token is not active")
+	}
+
+	return t, nil
+}
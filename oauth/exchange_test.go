@@ -0,0 +1,95 @@
+package oauth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+var orders = &models.Client{ID: "orders", ExchangeAudiences: []string{"billing"}}
+
+func TestExchangeAudience(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *models.Client
+		audience string
+		code     string
+	}{
+		{name: "an allowed audience", client: orders, audience: "billing"},
+		{name: "an audience outside the allow-list", client: orders, audience: "payroll", code: InvalidTarget},
+		{name: "no audience", client: orders, code: InvalidRequest},
+		{name: "a public client", client: &models.Client{ID: "cli", Public: true, ExchangeAudiences: []string{"billing"}}, audience: "billing", code: UnauthorizedClient},
+	}
+
+	for _, test := range tests {
+		e := checkExchangeAudience(test.client, test.audience)
+		if test.code == "" && e != nil {
+			t.Errorf("%s: unexpected error %v", test.name, e)
+		}
+		if test.code != "" && (e == nil || e.Code != test.code) {
+			t.Errorf("%s: expected %s, got %v", test.name, test.code, e)
+		}
+	}
+}
+
+func TestExchangeSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject *models.AccessToken
+		code    string
+	}{
+		{name: "a user token", subject: &models.AccessToken{ClientID: "app", UserID: 42}},
+		{name: "a token exchanged for the client", subject: &models.AccessToken{ClientID: "gateway", UserID: 42, Audience: "orders"}},
+		{name: "a token exchanged for another service", subject: &models.AccessToken{ClientID: "gateway", UserID: 42, Audience: "billing"}, code: InvalidGrant},
+		{name: "a client token", subject: &models.AccessToken{ClientID: "gateway"}, code: InvalidRequest},
+	}
+
+	for _, test := range tests {
+		e := checkExchangeSubject(orders, test.subject)
+		if test.code == "" && e != nil {
+			t.Errorf("%s: unexpected error %v", test.name, e)
+		}
+		if test.code != "" && (e == nil || e.Code != test.code) {
+			t.Errorf("%s: expected %s, got %v", test.name, test.code, e)
+		}
+	}
+}
+
+func TestExchangeActor(t *testing.T) {
+	subject := &models.AccessToken{ClientID: "gateway", UserID: 42, Audience: "orders", Actor: &models.Actor{Subject: "gateway"}}
+
+	tests := []struct {
+		name  string
+		actor *models.AccessToken
+		chain string
+		code  string
+	}{
+		{name: "no actor token", chain: `{"sub":"orders","act":{"sub":"gateway"}}`},
+		{name: "the client's own token", actor: &models.AccessToken{ClientID: "orders"}, chain: `{"sub":"orders","act":{"sub":"gateway"}}`},
+		{name: "a user token of the client", actor: &models.AccessToken{ClientID: "orders", UserID: 7}, chain: `{"sub":"7","act":{"sub":"orders","act":{"sub":"gateway"}}}`},
+		{name: "a token of another client", actor: &models.AccessToken{ClientID: "billing"}, code: InvalidGrant},
+	}
+
+	for _, test := range tests {
+		actor, e := exchangeActor(orders, subject, test.actor)
+		if test.code != "" {
+			if e == nil || e.Code != test.code {
+				t.Errorf("%s: expected %s, got %v", test.name, test.code, e)
+			}
+			continue
+		}
+		if e != nil {
+			t.Errorf("%s: unexpected error %v", test.name, e)
+			continue
+		}
+
+		chain, err := json.Marshal(actor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(chain) != test.chain {
+			t.Errorf("%s: expected the chain %s, got %s", test.name, test.chain, chain)
+		}
+	}
+}
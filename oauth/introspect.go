@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// IntrospectionResponse : state of a token (RFC 7662 section 2.2). Only active
+// is set for inactive tokens, whatever the reason.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Expiry    int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+
+	// Set on exchanged tokens (RFC 8693 sections 4.1 and 4.3)
+	Audience string        `json:"aud,omitempty"`
+	Actor    *models.Actor `json:"act,omitempty"`
+}
+
+var inactive = &IntrospectionResponse{Active: false}
+
+// Introspect returns the state of an access or refresh token. The hint tells
+// which kind to look up first (RFC 7009 section 2.1 values).
+func Introspect(db *database.Client, token, hint string) *IntrospectionResponse {
+	if hint == "refresh_token" {
+		if resp := introspectRefreshToken(db, token); resp != nil {
+			return resp
+		}
+		if resp := introspectAccessToken(db, token); resp != nil {
+			return resp
+		}
+		return inactive
+	}
+
+	if resp := introspectAccessToken(db, token); resp != nil {
+		return resp
+	}
+	if resp := introspectRefreshToken(db, token); resp != nil {
+		return resp
+	}
+	return inactive
+}
+
+func introspectAccessToken(db *database.Client, token string) *IntrospectionResponse {
+	t, err := db.GetAccessToken(token)
+	if err != nil {
+		return nil
+	}
+	if !t.Active() {
+		return inactive
+	}
+
+	return &IntrospectionResponse{
+		Active:    true,
+		Scope:     t.Scope,
+		ClientID:  t.ClientID,
+		Subject:   t.Subject(),
+		Expiry:    t.ExpiresAt.Unix(),
+		IssuedAt:  t.Created.Unix(),
+		TokenType: "Bearer",
+		Audience:  t.Audience,
+		Actor:     t.Actor,
+	}
+}
+
+func introspectRefreshToken(db *database.Client, token string) *IntrospectionResponse {
+	t, err := db.GetRefreshToken(token)
+	if err != nil {
+		return nil
+	}
+	if t.Used || t.Revoked || t.Expired() {
+		return inactive
+	}
+
+	return &IntrospectionResponse{
+		Active:    true,
+		Scope:     t.Scope,
+		ClientID:  t.ClientID,
+		Subject:   Subject(t.UserID),
+		Expiry:    t.ExpiresAt.Unix(),
+		IssuedAt:  t.Created.Unix(),
+		TokenType: "refresh_token",
+	}
+}
@@ -0,0 +1,39 @@
+package oauth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestInactiveTokensLeakNothing(t *testing.T) {
+	data, err := json.Marshal(inactive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"active":false}` {
+		t.Errorf("inactive tokens should only be described as inactive, got %s", data)
+	}
+}
+
+func TestDelegationChain(t *testing.T) {
+	resp := &IntrospectionResponse{
+		Active:   true,
+		Subject:  "42",
+		Audience: "billing",
+		Actor: &models.Actor{
+			Subject: "orders",
+			Act:     &models.Actor{Subject: "gateway"},
+		},
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"active":true,"sub":"42","aud":"billing","act":{"sub":"orders","act":{"sub":"gateway"}}}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
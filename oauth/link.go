@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+// StartLink creates the link request sending a logged in user through the
+// login of another provider, and returns the login URL
+func StartLink(db *database.Client, session *models.Session, provider string) (string, error) {
+	requestID, err := RandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	loginURL, err := providers.LoginURL(provider, requestID)
+	if err != nil {
+		return "", err
+	}
+
+	err = db.CreateLinkRequest(&models.LinkRequest{
+		ID:        requestID,
+		UserID:    session.UserID,
+		SID:       session.SID,
+		Provider:  provider,
+		ExpiresAt: time.Now().Add(RequestLifetime),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return loginURL, nil
+}
+
+// CompleteLink links the provider account the user logged in with to the
+// user who started the link request. The provider login URL can be sent to
+// anyone, so the link is only completed for the session that started it.
+func CompleteLink(db *database.Client, link *models.LinkRequest, session *models.Session, p providers.Provider, params map[string]string) (*models.User, error) {
+	if session == nil || session.SID != link.SID || session.UserID != link.UserID {
+		return nil, errors.New("the link was started from another session")
+	}
+
+	// a link request can only be completed once
+	if err := db.DeleteLinkRequest(link.ID); err != nil {
+		return nil, errors.New("unknown link request")
+	}
+	if link.Expired() {
+		return nil, errors.New("the link request has expired")
+	}
+	if link.Provider != p.Name() {
+		return nil, errors.New("the link request was started for another provider")
+	}
+	if params["error"] != "" {
+		return nil, fmt.Errorf("%s login failed: %s", p.DisplayName(), params["error"])
+	}
+
+	return providers.Link(db, p, params, link.UserID)
+}
@@ -0,0 +1,234 @@
+package oauth
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/models"
+)
+
+const (
+	// LogoutTokenLifetime : validity of the logout tokens, signed again for
+	// each delivery attempt
+	LogoutTokenLifetime = 2 * time.Minute
+
+	// MaxLogoutAttempts : number of deliveries of a logout notification before
+	// giving up. Retries are 2, 4, 8, 16 and 32 minutes apart.
+	MaxLogoutAttempts = 6
+
+	// BackchannelLogoutEvent : event of the logout tokens (OpenID Connect
+	// Back-Channel Logout section 2.4)
+	BackchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+)
+
+// LogoutTokenClaims : claims of a logout token (OpenID Connect Back-Channel
+// Logout section 2.4)
+type LogoutTokenClaims struct {
+	Issuer   string                 `json:"iss"`
+	Subject  string                 `json:"sub"`
+	Audience string                 `json:"aud"`
+	IssuedAt int64                  `json:"iat"`
+	Expiry   int64                  `json:"exp"`
+	JTI      string                 `json:"jti"`
+	Events   map[string]interface{} `json:"events"`
+	SID      string                 `json:"sid"`
+}
+
+var logoutClient = &http.Client{Timeout: 5 * time.Second}
+
+// ParseIDTokenHint checks that an ID token was issued by GOAuth and returns
+// its claims. Expired ID tokens are accepted, as logging out is the usual
+// reason to send an old one.
+func ParseIDTokenHint(db *database.Client, hint string) (*IDTokenClaims, error) {
+	published, err := keys.Published(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseIDTokenHint(hint, published)
+}
+
+func parseIDTokenHint(hint string, published *jwt.JWKSet) (*IDTokenClaims, error) {
+	claims := IDTokenClaims{}
+	header, err := jwt.Verify(hint, published.KeyFor, &claims)
+	if err != nil {
+		return nil, err
+	}
+	// logout tokens are signed with the same keys (RFC 8725 section 3.11)
+	if header.Typ != "JWT" {
+		return nil, errors.New("the token isn't an ID token")
+	}
+
+	iss, err := Issuer()
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer != iss {
+		return nil, errors.New("the ID token wasn't issued by GOAuth")
+	}
+
+	return &claims, nil
+}
+
+// LogoutConfirmationToken returns the CSRF token of the page asking the user
+// of a session to confirm he wants to log out, bound to the session
+func LogoutConfirmationToken(session *models.Session) (string, error) {
+	mac, err := keys.MAC([]byte("logout " + session.SID))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(mac), nil
+}
+
+// CheckLogoutConfirmation returns true if the user of the session confirmed
+// he wants to log out on the confirmation page. Without an id_token_hint, any
+// site could log him out otherwise (RP-Initiated Logout section 2).
+func CheckLogoutConfirmation(session *models.Session, token string) bool {
+	expected, err := LogoutConfirmationToken(session)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// EndSessionCookie returns the Set-Cookie header value deleting the session
+// cookie
+func EndSessionCookie() string {
+	return sessionCookie("", 0)
+}
+
+// EndSession ends a GOAuth session, and queues the notifications of the
+// clients that took part in it and registered a back-channel logout URI. They
+// are sent by RetryLogoutDeliveries, so the user doesn't wait for the clients.
+func EndSession(db *database.Client, session *models.Session) error {
+	clientIDs, err := db.DeleteSession(session.SID)
+	if err != nil {
+		return err
+	}
+
+	for _, clientID := range clientIDs {
+		client, err := db.GetClient(clientID)
+		if err != nil || client.BackchannelLogoutURI == "" {
+			continue
+		}
+
+		delivery := &models.LogoutDelivery{
+			ClientID:    client.ID,
+			UserID:      session.UserID,
+			SID:         session.SID,
+			NextAttempt: time.Now(),
+		}
+		if err = db.CreateLogoutDelivery(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RetryLogoutDeliveries sends the queued notifications and the failed ones
+// due for a new attempt, and returns how many failed
+func RetryLogoutDeliveries(db *database.Client) (int, error) {
+	deliveries, err := db.ListDueLogoutDeliveries(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for _, delivery := range deliveries {
+		if DeliverLogout(db, delivery) != nil {
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// DeliverLogout posts a logout token to the client, and schedules the next
+// attempt when it fails
+func DeliverLogout(db *database.Client, delivery *models.LogoutDelivery) error {
+	err := sendLogoutToken(db, delivery)
+	if err == nil {
+		return db.DeleteLogoutDelivery(delivery.ID)
+	}
+
+	delivery.Attempts++
+	if delivery.Attempts >= MaxLogoutAttempts {
+		db.DeleteLogoutDelivery(delivery.ID)
+		return fmt.Errorf("giving up notifying %s: %v", delivery.ClientID, err)
+	}
+
+	delivery.NextAttempt = time.Now().Add(time.Minute << uint(delivery.Attempts))
+	if updateErr := db.UpdateLogoutDelivery(delivery); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+func sendLogoutToken(db *database.Client, delivery *models.LogoutDelivery) error {
+	client, err := db.GetClient(delivery.ClientID)
+	if err != nil {
+		return err
+	}
+	// the client stopped listening since the session ended
+	if client.BackchannelLogoutURI == "" {
+		return nil
+	}
+
+	signer, kid, err := keys.Active(db)
+	if err != nil {
+		return err
+	}
+
+	token, err := newLogoutToken(delivery, signer, kid)
+	if err != nil {
+		return err
+	}
+
+	resp, err := logoutClient.PostForm(client.BackchannelLogoutURI, url.Values{"logout_token": {token}})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected response from %s: %s", client.BackchannelLogoutURI, resp.Status)
+	}
+	return nil
+}
+
+// newLogoutToken returns the logout token of a notification, signed with the
+// active key
+func newLogoutToken(delivery *models.LogoutDelivery, signer crypto.Signer, kid string) (string, error) {
+	iss, err := Issuer()
+	if err != nil {
+		return "", err
+	}
+
+	jti, err := RandomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := LogoutTokenClaims{
+		Issuer:   iss,
+		Subject:  Subject(delivery.UserID),
+		Audience: delivery.ClientID,
+		IssuedAt: now.Unix(),
+		Expiry:   now.Add(LogoutTokenLifetime).Unix(),
+		JTI:      jti,
+		Events:   map[string]interface{}{BackchannelLogoutEvent: struct{}{}},
+		SID:      delivery.SID,
+	}
+
+	return jwt.SignWithType(claims, signer, kid, "logout+jwt")
+}
@@ -0,0 +1,103 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestIssuingLogoutToken(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer = "https://auth.example.com"
+
+	token, err := newLogoutToken(&models.LogoutDelivery{
+		ClientID: "app",
+		UserID:   42,
+		SID:      "session",
+	}, signer, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := jwt.NewJWK(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.Kid = "test"
+	published := &jwt.JWKSet{Keys: []*jwt.JWK{key}}
+
+	var claims LogoutTokenClaims
+	header, err := jwt.Verify(token, published.KeyFor, &claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Typ != "logout+jwt" {
+		t.Errorf("unexpected typ %s", header.Typ)
+	}
+	if claims.Subject != "42" || claims.Audience != "app" || claims.SID != "session" || claims.JTI == "" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if _, ok := claims.Events[BackchannelLogoutEvent]; !ok {
+		t.Error("the backchannel logout event is missing")
+	}
+}
+
+func TestLogoutTokenIsNotAnIDTokenHint(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer = "https://auth.example.com"
+
+	key, err := jwt.NewJWK(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.Kid = "test"
+	published := &jwt.JWKSet{Keys: []*jwt.JWK{key}}
+
+	idToken, err := jwt.Sign(IDTokenClaims{Issuer: issuer, Subject: "42"}, signer, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = parseIDTokenHint(idToken, published); err != nil {
+		t.Errorf("an ID token should be accepted: %v", err)
+	}
+
+	logoutToken, err := newLogoutToken(&models.LogoutDelivery{ClientID: "app", UserID: 42}, signer, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = parseIDTokenHint(logoutToken, published); err == nil {
+		t.Error("a logout token should be rejected")
+	}
+}
+
+func TestLogoutConfirmationIsBoundToSession(t *testing.T) {
+	os.Setenv("MASTER_KEY", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+
+	token, err := LogoutConfirmationToken(&models.Session{SID: "victim"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !CheckLogoutConfirmation(&models.Session{SID: "victim"}, token) {
+		t.Error("the token of the session should confirm the logout")
+	}
+	if CheckLogoutConfirmation(&models.Session{SID: "other"}, token) {
+		t.Error("the token of another session shouldn't confirm the logout")
+	}
+	if CheckLogoutConfirmation(&models.Session{SID: "victim"}, "") {
+		t.Error("a logout without token should be confirmed first")
+	}
+}
@@ -0,0 +1,123 @@
+package oauth
+
+import (
+	"crypto"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/keys"
+)
+
+// IDTokenLifetime : validity of the ID tokens
+const IDTokenLifetime = time.Hour
+
+// IDTokenClaims : claims of an ID token (OpenID Connect Core section 2)
+type IDTokenClaims struct {
+	Issuer   string   `json:"iss"`
+	Subject  string   `json:"sub"`
+	Audience string   `json:"aud"`
+	Expiry   int64    `json:"exp"`
+	IssuedAt int64    `json:"iat"`
+	AuthTime int64    `json:"auth_time"`
+	Nonce    string   `json:"nonce,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+	SID      string   `json:"sid,omitempty"`
+}
+
+var issuer string
+var signingAlgorithm string
+
+func init() {
+	issuer = os.Getenv("ISSUER")
+	signingAlgorithm = os.Getenv("SIGNING_KEY_ALGORITHM")
+	if signingAlgorithm == "" {
+		signingAlgorithm = jwt.RS256
+	}
+}
+
+// KeyPolicy returns how the signing keys are rotated: every 30 days, being
+// published a day before their activation, and kept until the last ID token
+// they signed expires. New keys use $SIGNING_KEY_ALGORITHM (RS256 or ES256).
+func KeyPolicy() keys.Policy {
+	return keys.Policy{
+		Algorithm:        signingAlgorithm,
+		RotationPeriod:   30 * 24 * time.Hour,
+		PublishDelay:     24 * time.Hour,
+		MaxTokenLifetime: IDTokenLifetime,
+	}
+}
+
+// Issuer returns the GOAuth issuer identifier, its public base URL
+func Issuer() (string, error) {
+	if issuer == "" {
+		return "", errors.New("$ISSUER should be set")
+	}
+	return issuer, nil
+}
+
+// Subject returns the sub claim identifying a user. It must stay consistent
+// with models.AccessToken.Subject.
+func Subject(userID int) string {
+	return strconv.Itoa(userID)
+}
+
+// authentication : how and when a user authenticated, as told by the ID token
+type authentication struct {
+	ClientID string
+	UserID   int
+	Nonce    string
+	Provider string
+	AuthTime time.Time
+	SID      string
+}
+
+// newIDToken returns the ID token of an authentication, signed with the
+// active key. The amr claim holds the upstream provider the user logged in
+// with.
+func newIDToken(auth *authentication, signer crypto.Signer, kid string) (string, error) {
+	iss, err := Issuer()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := IDTokenClaims{
+		Issuer:   iss,
+		Subject:  Subject(auth.UserID),
+		Audience: auth.ClientID,
+		Expiry:   now.Add(IDTokenLifetime).Unix(),
+		IssuedAt: now.Unix(),
+		AuthTime: auth.AuthTime.Unix(),
+		Nonce:    auth.Nonce,
+		SID:      auth.SID,
+	}
+	if auth.Provider != "" {
+		claims.AMR = []string{auth.Provider}
+	}
+
+	return jwt.Sign(claims, signer, kid)
+}
+
+// signIDToken returns the ID token of an authentication if the openid scope
+// was granted, and an empty string otherwise
+func signIDToken(db *database.Client, scope string, auth *authentication) (string, *Error) {
+	if !ScopeIncludes(scope, ScopeOpenID) {
+		return "", nil
+	}
+
+	signer, kid, err := keys.Active(db)
+	if err != nil {
+		return "", NewError(ServerError, "no signing key available")
+	}
+
+	idToken, err := newIDToken(auth, signer, kid)
+	if err != nil {
+		return "", NewError(ServerError, "couldn't issue the ID token")
+	}
+
+	return idToken, nil
+}
@@ -0,0 +1,110 @@
+package oauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/socialement-competents/goauth/jwt"
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestIssuingIDToken(t *testing.T) {
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer = "https://auth.example.com"
+
+	authTime := time.Now().Add(-time.Minute)
+	token, err := newIDToken(&authentication{
+		ClientID: "app",
+		UserID:   42,
+		Nonce:    "n-0S6_WzA2Mj",
+		Provider: models.GithubProvider,
+		AuthTime: authTime,
+	}, signer, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var claims IDTokenClaims
+	_, err = jwt.Verify(token, func(*jwt.Header) (crypto.PublicKey, error) {
+		return signer.Public(), nil
+	}, &claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "42" || claims.Audience != "app" || claims.Issuer != issuer {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if claims.Nonce != "n-0S6_WzA2Mj" || claims.AuthTime != authTime.Unix() || claims.AMR[0] != models.GithubProvider {
+		t.Errorf("unexpected authentication claims %+v", claims)
+	}
+}
+
+func TestUserInfoScopes(t *testing.T) {
+	user := &models.User{
+		ID:     7,
+		GHUser: &models.GHUser{Login: "potato", Name: "Miguel", Email: "miguel@example.com", EmailVerified: true},
+	}
+
+	info := NewUserInfo(user, "openid")
+	if info.Subject != "7" || info.Name != "" || info.Email != "" {
+		t.Errorf("openid alone should only give the subject: %+v", info)
+	}
+
+	info = NewUserInfo(user, "openid profile email")
+	if info.Name != "Miguel" || info.PreferredUsername != "potato" || info.Email != "miguel@example.com" {
+		t.Errorf("unexpected claims %+v", info)
+	}
+	if info.EmailVerified == nil || !*info.EmailVerified {
+		t.Error("the email verified by GitHub should be verified")
+	}
+}
+
+func TestFilteringUser(t *testing.T) {
+	user := &models.User{
+		ID:         7,
+		GHUser:     &models.GHUser{Login: "potato", Email: "miguel@example.com"},
+		FitBitUser: &models.FitBitUser{RawPayload: "{}"},
+	}
+
+	filtered := FilterUser(user, &models.AccessToken{UserID: 7, Scope: "profile"})
+	if filtered.Login != "potato" || filtered.GHUser.Email != "" || filtered.RawPayload != "" {
+		t.Errorf("profile shouldn't give the email nor the raw payload: %+v %+v", filtered.GHUser, filtered.FitBitUser)
+	}
+	if user.GHUser.Email == "" {
+		t.Error("the user should not be modified")
+	}
+
+	filtered = FilterUser(user, &models.AccessToken{ClientID: "service", Scope: "admin"})
+	if filtered.GHUser.Email == "" || filtered.RawPayload == "" {
+		t.Error("admin client tokens should see everything")
+	}
+
+	filtered = FilterUser(user, &models.AccessToken{UserID: 7, Scope: "profile admin"})
+	if filtered.GHUser.Email != "" || filtered.RawPayload != "" {
+		t.Error("user tokens should never be admin")
+	}
+}
+
+func TestBearerChallenges(t *testing.T) {
+	resp, _ := BearerErrorResponse(ErrNoToken, ScopeOpenID)
+	if resp.StatusCode != http.StatusUnauthorized || resp.Headers["WWW-Authenticate"] != `Bearer realm="goauth"` {
+		t.Errorf("unexpected challenge without token: %d %s", resp.StatusCode, resp.Headers["WWW-Authenticate"])
+	}
+
+	resp, _ = BearerErrorResponse(NewError(InsufficientScope, "nope"), ScopeOpenID)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", resp.StatusCode)
+	}
+	if resp.Headers["WWW-Authenticate"] != `Bearer realm="goauth", error="insufficient_scope", error_description="nope", scope="openid"` {
+		t.Errorf("unexpected challenge %s", resp.Headers["WWW-Authenticate"])
+	}
+}
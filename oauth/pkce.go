@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"os"
+	"regexp"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// Code challenge methods defined by RFC 7636
+const (
+	PKCEPlain = "plain"
+	PKCES256  = "S256"
+)
+
+// code verifiers are 43 to 128 unreserved characters (RFC 7636 section 4.1),
+// and so are S256 challenges
+var pkcePattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// The plain method offers no protection if the authorization request leaks,
+// it has to be enabled explicitly with $PKCE_ALLOW_PLAIN
+var allowPlainPKCE bool
+
+func init() {
+	allowPlainPKCE = os.Getenv("PKCE_ALLOW_PLAIN") == "true"
+}
+
+// checkCodeChallenge validates the PKCE parameters of an authorization request
+// and returns the challenge method to store
+func checkCodeChallenge(challenge, method string, required bool) (string, *Error) {
+	if challenge == "" {
+		if required {
+			return "", NewError(InvalidRequest, "public clients must use PKCE (code_challenge)")
+		}
+		if method != "" {
+			return "", NewError(InvalidRequest, "code_challenge_method requires a code_challenge")
+		}
+		return "", nil
+	}
+
+	// RFC 7636 section 4.3: plain is the default method
+	if method == "" {
+		method = PKCEPlain
+	}
+
+	switch {
+	case method != PKCES256 && method != PKCEPlain:
+		return "", NewError(InvalidRequest, "unsupported code_challenge_method")
+	case method == PKCEPlain && !allowPlainPKCE:
+		return "", NewError(InvalidRequest, "the plain code_challenge_method is not allowed, use S256")
+	case !pkcePattern.MatchString(challenge):
+		return "", NewError(InvalidRequest, "malformed code_challenge")
+	}
+
+	return method, nil
+}
+
+// verifyCodeVerifier checks the verifier sent to the token endpoint against
+// the challenge stored with the code (RFC 7636 section 4.6)
+func verifyCodeVerifier(code *models.AuthorizationCode, verifier string) *Error {
+	if code.CodeChallenge == "" {
+		if verifier != "" {
+			return NewError(InvalidGrant, "no code_challenge was sent for this code")
+		}
+		return nil
+	}
+
+	if !pkcePattern.MatchString(verifier) {
+		return NewError(InvalidGrant, "missing or malformed code_verifier")
+	}
+
+	computed := verifier
+	if code.CodeChallengeMethod == PKCES256 {
+		sum := sha256.Sum256([]byte(verifier))
+		computed = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	if subtle.ConstantTimeCompare([]byte(computed), []byte(code.CodeChallenge)) != 1 {
+		return NewError(InvalidGrant, "code_verifier doesn't match the code_challenge")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// Example from RFC 7636 appendix B
+const (
+	testVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestCheckingCodeChallenge(t *testing.T) {
+	if _, e := checkCodeChallenge("", "", true); e == nil {
+		t.Error("PKCE should be required for public clients")
+	}
+
+	if method, e := checkCodeChallenge(testChallenge, PKCES256, true); e != nil || method != PKCES256 {
+		t.Errorf("a S256 challenge should be accepted, got %s (%v)", method, e)
+	}
+
+	allowPlainPKCE = false
+	if _, e := checkCodeChallenge(testVerifier, "", false); e == nil {
+		t.Error("plain, the default method, should be disabled by default")
+	}
+
+	allowPlainPKCE = true
+	defer func() { allowPlainPKCE = false }()
+	if method, e := checkCodeChallenge(testVerifier, "", false); e != nil || method != PKCEPlain {
+		t.Errorf("plain should be the default method, got %s (%v)", method, e)
+	}
+}
+
+func TestVerifyingCodeVerifier(t *testing.T) {
+	code := &models.AuthorizationCode{
+		CodeChallenge:       testChallenge,
+		CodeChallengeMethod: PKCES256,
+	}
+
+	if e := verifyCodeVerifier(code, testVerifier); e != nil {
+		t.Errorf("the RFC 7636 verifier should match: %v", e)
+	}
+	if e := verifyCodeVerifier(code, testChallenge); e == nil || e.Code != InvalidGrant {
+		t.Errorf("expected %s, got %v", InvalidGrant, e)
+	}
+	if e := verifyCodeVerifier(code, ""); e == nil {
+		t.Error("the verifier should be required when a challenge was sent")
+	}
+	if e := verifyCodeVerifier(&models.AuthorizationCode{}, ""); e != nil {
+		t.Errorf("codes without challenge don't need a verifier: %v", e)
+	}
+}
@@ -0,0 +1,15 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// RandomString returns a URL-safe random string built from n random bytes
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,50 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// RedirectURL adds the parameters to the query of a redirection URI, keeping
+// the ones it already has
+func RedirectURL(uri string, params url.Values) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			if value != "" {
+				query.Add(key, value)
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// ErrorRedirectURL returns the redirection URI carrying an error, as described
+// in RFC 6749 section 4.1.2.1
+func ErrorRedirectURL(uri, state string, e *Error) (string, error) {
+	return RedirectURL(uri, url.Values{
+		"error":             {e.Code},
+		"error_description": {e.Description},
+		"state":             {state},
+	})
+}
+
+// Redirect returns a response redirecting the user agent
+func Redirect(location string) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusFound,
+		Headers: map[string]string{
+			"Location":      location,
+			"Cache-Control": "no-store",
+		},
+	}, nil
+}
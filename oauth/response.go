@@ -0,0 +1,48 @@
+package oauth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// JSONResponse returns the payload as a JSON response that must not be cached,
+// as required for tokens and credentials (RFC 6749 section 5.1)
+func JSONResponse(code int, payload interface{}) (events.APIGatewayProxyResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		code = http.StatusInternalServerError
+		body, _ = json.Marshal(NewError(ServerError, "couldn't format the response"))
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: code,
+		Headers: map[string]string{
+			"Content-Type":  "application/json;charset=UTF-8",
+			"Cache-Control": "no-store",
+			"Pragma":        "no-cache",
+		},
+		Body: string(body),
+	}, nil
+}
+
+// PublicJSONResponse returns the payload as a JSON response that can be
+// cached for an hour, for public documents like the server metadata
+func PublicJSONResponse(payload interface{}) (events.APIGatewayProxyResponse, error) {
+	resp, err := JSONResponse(http.StatusOK, payload)
+	if resp.StatusCode == http.StatusOK {
+		resp.Headers["Cache-Control"] = "public, max-age=3600"
+		delete(resp.Headers, "Pragma")
+	}
+	return resp, err
+}
+
+// ErrorResponse returns the error as described in RFC 6749 section 5.2
+func ErrorResponse(e *Error) (events.APIGatewayProxyResponse, error) {
+	resp, err := JSONResponse(e.StatusCode(), e)
+	if e.Code == InvalidClient {
+		resp.Headers["WWW-Authenticate"] = `Basic realm="goauth"`
+	}
+	return resp, err
+}
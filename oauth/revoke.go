@@ -0,0 +1,67 @@
+package oauth
+
+import (
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// tokenRevoker : the token operations of the database revocation uses
+type tokenRevoker interface {
+	GetAccessToken(token string) (*models.AccessToken, error)
+	GetRefreshToken(token string) (*models.RefreshToken, error)
+	RevokeAccessToken(token string) error
+	RevokeTokenFamily(familyID string) error
+}
+
+// Revoke revokes an access or refresh token issued to the client (RFC 7009).
+// Revoking a refresh token also revokes every token refreshed from the same
+// authorization. Unknown tokens are ignored, as required by section 2.2.
+func Revoke(db *database.Client, client *models.Client, token, hint string) *Error {
+	return revoke(db, client, token, hint)
+}
+
+func revoke(db tokenRevoker, client *models.Client, token, hint string) *Error {
+	if hint == "refresh_token" {
+		if found, e := revokeRefreshToken(db, client, token); found {
+			return e
+		}
+		_, e := revokeAccessToken(db, client, token)
+		return e
+	}
+
+	if found, e := revokeAccessToken(db, client, token); found {
+		return e
+	}
+	_, e := revokeRefreshToken(db, client, token)
+	return e
+}
+
+func revokeAccessToken(db tokenRevoker, client *models.Client, token string) (bool, *Error) {
+	t, err := db.GetAccessToken(token)
+	if err != nil {
+		return false, nil
+	}
+	if t.ClientID != client.ID {
+		return true, NewError(UnauthorizedClient, "the token was issued to another client")
+	}
+
+	if err = db.RevokeAccessToken(token); err != nil {
+		return true, NewError(ServerError, "couldn't revoke the token")
+	}
+	return true, nil
+}
+
+func revokeRefreshToken(db tokenRevoker, client *models.Client, token string) (bool, *Error) {
+	t, err := db.GetRefreshToken(token)
+	if err != nil {
+		return false, nil
+	}
+	if t.ClientID != client.ID {
+		return true, NewError(UnauthorizedClient, "the token was issued to another client")
+	}
+
+	if err = db.RevokeTokenFamily(t.FamilyID); err != nil {
+		return true, NewError(ServerError, "couldn't revoke the token")
+	}
+	return true, nil
+}
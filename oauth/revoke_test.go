@@ -0,0 +1,90 @@
+package oauth
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+// revokedTokens : an in-memory tokenRevoker recording what was revoked
+type revokedTokens struct {
+	access  map[string]*models.AccessToken
+	refresh map[string]*models.RefreshToken
+
+	revokedAccess   []string
+	revokedFamilies []string
+}
+
+func (r *revokedTokens) GetAccessToken(token string) (*models.AccessToken, error) {
+	if t, ok := r.access[token]; ok {
+		return t, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *revokedTokens) GetRefreshToken(token string) (*models.RefreshToken, error) {
+	if t, ok := r.refresh[token]; ok {
+		return t, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *revokedTokens) RevokeAccessToken(token string) error {
+	r.revokedAccess = append(r.revokedAccess, token)
+	return nil
+}
+
+func (r *revokedTokens) RevokeTokenFamily(familyID string) error {
+	r.revokedFamilies = append(r.revokedFamilies, familyID)
+	return nil
+}
+
+func TestRevoking(t *testing.T) {
+	client := &models.Client{ID: "app"}
+
+	tests := []struct {
+		name          string
+		token, hint   string
+		code          string
+		revokedAccess string
+		revokedFamily string
+	}{
+		{name: "access token", token: "access", revokedAccess: "access"},
+		{name: "access token with a refresh hint", token: "access", hint: "refresh_token", revokedAccess: "access"},
+		{name: "refresh token revokes its family", token: "refresh", revokedFamily: "family"},
+		{name: "refresh token with a refresh hint", token: "refresh", hint: "refresh_token", revokedFamily: "family"},
+		{name: "access token of another client", token: "other-access", code: UnauthorizedClient},
+		{name: "refresh token of another client", token: "other-refresh", code: UnauthorizedClient},
+		{name: "unknown token is ignored", token: "unknown"},
+	}
+
+	for _, test := range tests {
+		db := &revokedTokens{
+			access: map[string]*models.AccessToken{
+				"access":       {Token: "access", ClientID: "app"},
+				"other-access": {Token: "other-access", ClientID: "other"},
+			},
+			refresh: map[string]*models.RefreshToken{
+				"refresh":       {Token: "refresh", ClientID: "app", FamilyID: "family"},
+				"other-refresh": {Token: "other-refresh", ClientID: "other", FamilyID: "other-family"},
+			},
+		}
+
+		e := revoke(db, client, test.token, test.hint)
+		switch {
+		case test.code == "" && e != nil:
+			t.Errorf("%s: unexpected error %v", test.name, e)
+		case test.code != "" && (e == nil || e.Code != test.code):
+			t.Errorf("%s: expected %s, got %v", test.name, test.code, e)
+		}
+
+		if revoked := strings.Join(db.revokedAccess, " "); revoked != test.revokedAccess {
+			t.Errorf("%s: expected %q to be revoked, got %q", test.name, test.revokedAccess, revoked)
+		}
+		if revoked := strings.Join(db.revokedFamilies, " "); revoked != test.revokedFamily {
+			t.Errorf("%s: expected the family %q to be revoked, got %q", test.name, test.revokedFamily, revoked)
+		}
+	}
+}
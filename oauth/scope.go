@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"strings"
+)
+
+// ParseScope splits a space-delimited scope string (RFC 6749 section 3.3)
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// FormatScope joins scopes into a space-delimited scope string
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// ScopeIncludes returns true if every requested scope has been granted
+func ScopeIncludes(granted, requested string) bool {
+	grantedScopes := ParseScope(granted)
+	for _, r := range ParseScope(requested) {
+		found := false
+		for _, g := range grantedScopes {
+			if g == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Scopes with a meaning for GOAuth itself (OpenID Connect Core section 5.4)
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+
+	// ScopeAdmin gives access to every user, for GOAuth's own tools. It is
+	// only granted to clients on their own behalf (client credentials), as a
+	// user can't delegate an authority he doesn't have.
+	ScopeAdmin = "admin"
+
+	// ScopeUpstreamPrefix : prefix of the scopes giving the provider tokens of
+	// the user, followed by the provider name
+	ScopeUpstreamPrefix = "upstream:"
+)
+
+// checkUserScopes returns an invalid_scope error if scopes requested on
+// behalf of a user include a client only scope
+func checkUserScopes(scopes []string) *Error {
+	if contains(scopes, ScopeAdmin) {
+		return NewError(InvalidScope, "the admin scope is only granted with the client credentials grant")
+	}
+	return nil
+}
+
+// UpstreamScope returns the scope giving the user tokens of a provider
+func UpstreamScope(provider string) string {
+	return ScopeUpstreamPrefix + provider
+}
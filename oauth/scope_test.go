@@ -0,0 +1,27 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestScopeIncludes(t *testing.T) {
+	if !ScopeIncludes("openid profile email", "email  openid") {
+		t.Error("a narrower scope should be included")
+	}
+	if ScopeIncludes("openid profile", "openid email") {
+		t.Error("a wider scope shouldn't be included")
+	}
+	if !ScopeIncludes("openid", "") {
+		t.Error("an empty scope is always included")
+	}
+}
+
+func TestUpstreamScopesAreDescribed(t *testing.T) {
+	for _, provider := range []string{models.GithubProvider, models.FitBitProvider} {
+		if _, ok := ScopeDescriptions[UpstreamScope(provider)]; !ok {
+			t.Errorf("the consent page should describe the %s upstream scope", provider)
+		}
+	}
+}
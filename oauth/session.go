@@ -0,0 +1,132 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/models"
+)
+
+const (
+	// SessionCookie : name of the GOAuth session cookie
+	SessionCookie = "goauth_session"
+
+	// SessionLifetime : time during which a user isn't sent through the
+	// provider login again
+	SessionLifetime = 7 * 24 * time.Hour
+)
+
+// StartSession creates the GOAuth session of a user who just logged in with a
+// provider, and returns it with the Set-Cookie header value identifying it
+func StartSession(db *database.Client, user *models.User, provider string) (*models.Session, string, error) {
+	id, err := RandomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sid, err := RandomString(16)
+	if err != nil {
+		return nil, "", err
+	}
+
+	value, err := signSessionID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := &models.Session{
+		ID:        id,
+		SID:       sid,
+		UserID:    user.ID,
+		Provider:  provider,
+		AuthTime:  user.LastLogin,
+		ExpiresAt: time.Now().Add(SessionLifetime),
+	}
+	if err = db.CreateSession(session); err != nil {
+		return nil, "", err
+	}
+
+	return session, sessionCookie(value, int(SessionLifetime.Seconds())), nil
+}
+
+// CurrentSession returns the active session of the request cookie, or nil
+// when the user isn't logged in to GOAuth
+func CurrentSession(db *database.Client, request *events.APIGatewayProxyRequest) *models.Session {
+	header := http.Header{"Cookie": {Header(request, "Cookie")}}
+	cookie, err := (&http.Request{Header: header}).Cookie(SessionCookie)
+	if err != nil {
+		return nil
+	}
+
+	id, ok := verifySessionID(cookie.Value)
+	if !ok {
+		return nil
+	}
+
+	session, err := db.GetSession(id)
+	if err != nil || !session.Active() {
+		return nil
+	}
+
+	return session
+}
+
+// sessionCookie returns a Set-Cookie header value. The cookie is only sent
+// back on top-level navigations from other sites (SameSite=Lax), which is how
+// applications send their users to /authorize.
+func sessionCookie(value string, maxAge int) string {
+	return fmt.Sprintf(
+		"%s=%s; Path=/; Max-Age=%d; HttpOnly; Secure; SameSite=Lax",
+		SessionCookie,
+		value,
+		maxAge,
+	)
+}
+
+// The cookie holds the session ID and its MAC, so that forged cookies are
+// rejected without a database lookup
+func signSessionID(id string) (string, error) {
+	mac, err := keys.MAC([]byte(id))
+	if err != nil {
+		return "", err
+	}
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac), nil
+}
+
+func verifySessionID(value string) (string, bool) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return "", false
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !keys.CheckMAC([]byte(parts[0]), mac) {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// CompleteLogin starts the session of a user who just logged in with a
+// provider, completes the authorization request he logged in for, and
+// returns the response redirecting him with the session cookie
+func CompleteLogin(db *database.Client, requestID string, user *models.User, provider string) (events.APIGatewayProxyResponse, error) {
+	session, cookie, err := StartSession(db, user, provider)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("couldn't start the session: %v", err)
+	}
+
+	location, err := CompleteAuthorization(db, requestID, session)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	resp, err := Redirect(location)
+	resp.Headers["Set-Cookie"] = cookie
+	return resp, err
+}
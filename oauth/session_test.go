@@ -0,0 +1,56 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestSessionCookieSignature(t *testing.T) {
+	os.Setenv("MASTER_KEY", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+
+	value, err := signSessionID("session-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id, ok := verifySessionID(value); !ok || id != "session-id" {
+		t.Errorf("a signed session ID should be accepted, got %s", id)
+	}
+	if _, ok := verifySessionID("other-id" + value[len("session-id"):]); ok {
+		t.Error("a forged session ID should be rejected")
+	}
+	if _, ok := verifySessionID("session-id"); ok {
+		t.Error("an unsigned session ID should be rejected")
+	}
+}
+
+func TestCanUseSession(t *testing.T) {
+	session := &models.Session{
+		Provider:  models.GithubProvider,
+		AuthTime:  time.Now().Add(-time.Hour),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name     string
+		request  *models.AuthorizationRequest
+		session  *models.Session
+		expected bool
+	}{
+		{"no session", &models.AuthorizationRequest{MaxAge: -1}, nil, false},
+		{"session", &models.AuthorizationRequest{MaxAge: -1}, session, true},
+		{"prompt=login", &models.AuthorizationRequest{MaxAge: -1, Prompt: PromptLogin}, session, false},
+		{"recent enough", &models.AuthorizationRequest{MaxAge: 7200}, session, true},
+		{"too old", &models.AuthorizationRequest{MaxAge: 60}, session, false},
+		{"other provider", &models.AuthorizationRequest{MaxAge: -1, Provider: models.FitBitProvider}, session, false},
+	}
+	for _, test := range tests {
+		if got := CanUseSession(testClient, test.request, test.session); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
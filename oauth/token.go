@@ -0,0 +1,267 @@
+package oauth
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+)
+
+const (
+	// AccessTokenLifetime : default validity of the access tokens
+	AccessTokenLifetime = time.Hour
+
+	// RefreshTokenLifetime : default validity of the refresh tokens
+	RefreshTokenLifetime = 30 * 24 * time.Hour
+)
+
+// TokenResponse : successful token endpoint response (RFC 6749 section 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+
+	// Set by the token exchange grant (RFC 8693 section 2.2.1)
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+}
+
+// GrantHandler : handles a grant type for an authenticated client
+type GrantHandler func(*database.Client, *models.Client, url.Values) (*TokenResponse, *Error)
+
+// Grants : the grant types supported by the token endpoint
+var Grants = map[string]GrantHandler{
+	models.AuthorizationCodeGrant: ExchangeAuthorizationCode,
+	models.RefreshTokenGrant:      RefreshAccessToken,
+	models.ClientCredentialsGrant: IssueClientToken,
+	models.DeviceCodeGrant:        ExchangeDeviceCode,
+	models.TokenExchangeGrant:     ExchangeToken,
+}
+
+// ExchangeAuthorizationCode handles the authorization_code grant (RFC 6749
+// section 4.1.3) for an authenticated client
+func ExchangeAuthorizationCode(db *database.Client, client *models.Client, form url.Values) (*TokenResponse, *Error) {
+	code := form.Get("code")
+	if code == "" {
+		return nil, NewError(InvalidRequest, "code is required")
+	}
+
+	authCode, err := db.GetAuthorizationCode(code)
+	if err != nil {
+		return nil, NewError(InvalidGrant, "unknown authorization code")
+	}
+
+	switch {
+	case authCode.ClientID != client.ID:
+		return nil, NewError(InvalidGrant, "the code was issued to another client")
+	case authCode.Used:
+		return nil, replayedCode(db, authCode)
+	case authCode.Expired():
+		return nil, NewError(InvalidGrant, "the code has expired")
+	// redirect_uri is only required when it was sent to /authorize
+	case (authCode.RedirectURIGiven || form.Get("redirect_uri") != "") && authCode.RedirectURI != form.Get("redirect_uri"):
+		return nil, NewError(InvalidGrant, "redirect_uri doesn't match the authorization request")
+	}
+
+	if e := verifyCodeVerifier(authCode, form.Get("code_verifier")); e != nil {
+		return nil, e
+	}
+
+	// every token refreshed from this code will belong to the same family
+	familyID, err := RandomString(16)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't create the token family")
+	}
+
+	used, err := db.UseAuthorizationCode(code, familyID)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't use the code")
+	}
+	if !used {
+		// another exchange won the race, the code was replayed
+		if authCode, err = db.GetAuthorizationCode(code); err != nil {
+			return nil, NewError(ServerError, "couldn't read the code")
+		}
+		return nil, replayedCode(db, authCode)
+	}
+
+	idToken, e := signIDToken(db, authCode.Scope, &authentication{
+		ClientID: authCode.ClientID,
+		UserID:   authCode.UserID,
+		Nonce:    authCode.Nonce,
+		Provider: authCode.Provider,
+		AuthTime: authCode.AuthTime,
+		SID:      authCode.SID,
+	})
+	if e != nil {
+		return nil, e
+	}
+
+	resp, err := issueTokens(db, client, authCode.UserID, authCode.Scope, authCode.Scope, familyID)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't issue the tokens")
+	}
+	resp.IDToken = idToken
+
+	return resp, nil
+}
+
+// replayedCode revokes the tokens already issued for a code used twice, as
+// either the client or an attacker holds a stolen code (RFC 6749 section
+// 4.1.2)
+func replayedCode(db *database.Client, authCode *models.AuthorizationCode) *Error {
+	if authCode.FamilyID != "" {
+		if err := db.RevokeTokenFamily(authCode.FamilyID); err != nil {
+			return NewError(ServerError, "couldn't revoke the tokens of the replayed code")
+		}
+	}
+	return NewError(InvalidGrant, "the code has already been used")
+}
+
+// RefreshAccessToken handles the refresh_token grant (RFC 6749 section 6).
+// Refresh tokens are rotated on each use; replaying a rotated one revokes its
+// whole family, since either the client or an attacker holds a stolen token.
+func RefreshAccessToken(db *database.Client, client *models.Client, form url.Values) (*TokenResponse, *Error) {
+	token := form.Get("refresh_token")
+	if token == "" {
+		return nil, NewError(InvalidRequest, "refresh_token is required")
+	}
+
+	refreshToken, err := db.GetRefreshToken(token)
+	if err != nil || refreshToken.ClientID != client.ID {
+		return nil, NewError(InvalidGrant, "unknown refresh token")
+	}
+
+	if refreshToken.Revoked {
+		return nil, NewError(InvalidGrant, "the refresh token has been revoked")
+	}
+	if refreshToken.Expired() {
+		return nil, NewError(InvalidGrant, "the refresh token has expired")
+	}
+
+	scope := refreshToken.Scope
+	if requested := form.Get("scope"); requested != "" {
+		if !ScopeIncludes(scope, requested) {
+			return nil, NewError(InvalidScope, "the requested scope exceeds the granted one")
+		}
+		scope = FormatScope(ParseScope(requested))
+	}
+
+	used := false
+	if !refreshToken.Used {
+		if used, err = db.UseRefreshToken(token); err != nil {
+			return nil, NewError(ServerError, "couldn't rotate the refresh token")
+		}
+	}
+	if !used {
+		if err = db.RevokeTokenFamily(refreshToken.FamilyID); err != nil {
+			return nil, NewError(ServerError, "couldn't revoke the reused tokens")
+		}
+		return nil, NewError(InvalidGrant, "the refresh token has already been used")
+	}
+
+	resp, err := issueTokens(db, client, refreshToken.UserID, scope, refreshToken.Scope, refreshToken.FamilyID)
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't issue the tokens")
+	}
+
+	return resp, nil
+}
+
+// IssueClientToken handles the client_credentials grant (RFC 6749 section
+// 4.4): the token is issued to a confidential client on its own behalf, with
+// its machine scopes only
+func IssueClientToken(db *database.Client, client *models.Client, form url.Values) (*TokenResponse, *Error) {
+	scopes, e := machineScopes(client, form.Get("scope"))
+	if e != nil {
+		return nil, e
+	}
+
+	resp, err := issueTokens(db, client, 0, FormatScope(scopes), "", "")
+	if err != nil {
+		return nil, NewError(ServerError, "couldn't issue the token")
+	}
+
+	return resp, nil
+}
+
+// machineScopes returns the scopes granted to a client on its own behalf:
+// the requested ones, or all of its machine scopes by default
+func machineScopes(client *models.Client, requested string) ([]string, *Error) {
+	if client.Public {
+		return nil, NewError(UnauthorizedClient, "public clients can't use the client credentials grant")
+	}
+
+	scopes := ParseScope(requested)
+	if len(scopes) == 0 {
+		scopes = client.MachineScopes
+	}
+	if !client.AllowsMachineScopes(scopes) {
+		return nil, NewError(InvalidScope, "the requested scope is not allowed for this client")
+	}
+	return scopes, nil
+}
+
+// issueTokens creates a bearer token for the user (0 for the client itself),
+// along with a refresh token of the family when there is one. The access
+// token can be narrowed to a scope, the refresh token keeps the scope of the
+// whole grant (RFC 6749 section 6).
+func issueTokens(db *database.Client, client *models.Client, userID int, scope, grantScope, familyID string) (*TokenResponse, error) {
+	token, err := RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	accessLifetime := lifetime(client.AccessTokenLifetime, AccessTokenLifetime)
+	err = db.CreateAccessToken(&models.AccessToken{
+		Token:     token,
+		ClientID:  client.ID,
+		UserID:    userID,
+		Scope:     scope,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(accessLifetime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(accessLifetime.Seconds()),
+		Scope:       scope,
+	}
+
+	if familyID == "" {
+		return resp, nil
+	}
+
+	if resp.RefreshToken, err = RandomString(32); err != nil {
+		return nil, err
+	}
+
+	err = db.CreateRefreshToken(&models.RefreshToken{
+		Token:     resp.RefreshToken,
+		FamilyID:  familyID,
+		ClientID:  client.ID,
+		UserID:    userID,
+		Scope:     grantScope,
+		ExpiresAt: time.Now().Add(lifetime(client.RefreshTokenLifetime, RefreshTokenLifetime)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// lifetime returns the client specific lifetime, in seconds, if it has one
+func lifetime(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,41 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestMachineScopes(t *testing.T) {
+	service := &models.Client{ID: "service", MachineScopes: []string{"admin", "billing"}}
+	public := &models.Client{ID: "cli", Public: true, MachineScopes: []string{"billing"}}
+
+	tests := []struct {
+		name      string
+		client    *models.Client
+		requested string
+		scope     string
+		code      string
+	}{
+		{name: "every machine scope by default", client: service, scope: "admin billing"},
+		{name: "a narrower scope", client: service, requested: "billing", scope: "billing"},
+		{name: "a scope outside the machine scopes", client: service, requested: "billing openid", code: InvalidScope},
+		{name: "a public client", client: public, code: UnauthorizedClient},
+		{name: "a public client asking for its machine scopes", client: public, requested: "billing", code: UnauthorizedClient},
+	}
+
+	for _, test := range tests {
+		scopes, e := machineScopes(test.client, test.requested)
+		if test.code != "" {
+			if e == nil || e.Code != test.code {
+				t.Errorf("%s: expected %s, got %v", test.name, test.code, e)
+			}
+			continue
+		}
+		if e != nil {
+			t.Errorf("%s: unexpected error %v", test.name, e)
+		} else if FormatScope(scopes) != test.scope {
+			t.Errorf("%s: expected the scope %q, got %q", test.name, test.scope, FormatScope(scopes))
+		}
+	}
+}
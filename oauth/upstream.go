@@ -0,0 +1,52 @@
+package oauth
+
+import (
+	"database/sql"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+// UpstreamTokenResponse : a provider access token handed to a client, without
+// its refresh token
+type UpstreamTokenResponse struct {
+	Provider    string `json:"provider"`
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope,omitempty"`
+	ExpiresIn   int    `json:"expires_in,omitempty"`
+}
+
+// GetUpstreamToken returns a fresh provider token of the user of an access
+// token, when the client it was issued to is allowed to call the provider and
+// the user consented to it with the upstream scope of the provider
+func GetUpstreamToken(db *database.Client, token *models.AccessToken, provider string) (*UpstreamTokenResponse, *Error) {
+	if token.UserID == 0 {
+		return nil, NewError(InvalidToken, "the token wasn't issued on behalf of a user")
+	}
+	if e := RequireScope(token, UpstreamScope(provider)); e != nil {
+		return nil, e
+	}
+
+	client, err := db.GetClient(token.ClientID)
+	if err != nil || !client.AllowsUpstream(provider) {
+		return nil, NewError(UnauthorizedClient, "the client can't get the "+provider+" tokens of its users")
+	}
+
+	upstream, err := providers.FreshToken(db, token.UserID, provider)
+	if err == sql.ErrNoRows || err == providers.ErrRefreshRejected {
+		return nil, NewError(InvalidRequest, "the user has no "+provider+" token, he has to log in with "+providers.DisplayName(provider))
+	}
+	if err != nil {
+		return nil, NewError(TemporarilyUnavailable, "couldn't refresh the "+provider+" token")
+	}
+
+	return &UpstreamTokenResponse{
+		Provider:    provider,
+		AccessToken: upstream.AccessToken,
+		TokenType:   upstream.TokenType,
+		Scope:       upstream.Scope,
+		ExpiresIn:   upstream.ExpiresIn,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"github.com/socialement-competents/goauth/models"
+)
+
+// UserInfo : standard claims about a user (OpenID Connect Core section 5.1)
+type UserInfo struct {
+	Subject           string `json:"sub"`
+	Name              string `json:"name,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+// claims GOAuth can return, in the ID token or at the UserInfo endpoint
+var userInfoClaims = []string{
+	"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "amr",
+	"name", "picture", "preferred_username", "email", "email_verified",
+}
+
+// NewUserInfo returns the claims of the user that the scope gives access to
+func NewUserInfo(user *models.User, scope string) *UserInfo {
+	user.RemoveNils()
+	info := &UserInfo{Subject: Subject(user.ID)}
+
+	if ScopeIncludes(scope, ScopeProfile) {
+		info.Name = user.GetName()
+		info.Picture = user.GetImage()
+		info.PreferredUsername = user.GHUser.Login
+	}
+
+	if ScopeIncludes(scope, ScopeEmail) && user.GHUser.Email != "" {
+		verified := user.GHUser.EmailVerified
+		info.Email = user.GHUser.Email
+		info.EmailVerified = &verified
+	}
+
+	return info
+}
+
+// FilterUser returns a copy of the user with only the fields the token scope
+// gives access to. Admin client tokens see everything.
+func FilterUser(user *models.User, token *models.AccessToken) *models.User {
+	if RequireAdmin(token) == nil {
+		return user
+	}
+	scope := token.Scope
+
+	user.RemoveNils()
+	filtered := &models.User{
+		ID:         user.ID,
+		Provider:   user.Provider,
+		LastLogin:  user.LastLogin,
+		Created:    user.Created,
+		GHUser:     &models.GHUser{},
+		FitBitUser: &models.FitBitUser{},
+	}
+
+	if ScopeIncludes(scope, ScopeProfile) {
+		*filtered.GHUser = *user.GHUser
+		filtered.GHUser.Email = ""
+		filtered.GHUser.EmailVerified = false
+		*filtered.FitBitUser = *user.FitBitUser
+		filtered.FitBitUser.RawPayload = ""
+	}
+
+	if ScopeIncludes(scope, ScopeEmail) {
+		filtered.GHUser.Email = user.GHUser.Email
+		filtered.GHUser.EmailVerified = user.GHUser.EmailVerified
+	}
+
+	return filtered
+}
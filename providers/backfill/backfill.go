@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/models"
+	"github.com/socialement-competents/goauth/providers"
+)
+
+// Resolves the numeric ID of the GitHub identities still keyed by login
+// (migration 20), and stores it on the identity and the user. Logging in
+// upgrades an identity too, so this is only needed for the users who don't
+// log in again. It trusts that each login still belongs to the account that
+// used it to log in, so run it right after the migration. Logins renamed
+// since are reported and left out: their owners get a new account on their
+// next login.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only print what would be updated")
+	flag.Parse()
+
+	// an optional personal access token raises the GitHub rate limit
+	authorization := ""
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		authorization = "token " + token
+	}
+
+	client, err := database.NewClient()
+	if err != nil {
+		fmt.Println("connecting to the database failed: ", err)
+		return
+	}
+
+	identities, err := client.ListIdentitiesBySubjectPrefix(models.GithubProvider, providers.GitHubLegacyPrefix)
+	if err != nil {
+		panic(fmt.Sprintf("listing the identities failed: %v", err))
+	}
+
+	failed := 0
+	for _, identity := range identities {
+		login := strings.TrimPrefix(identity.Subject, providers.GitHubLegacyPrefix)
+		if err = backfill(client, identity, login, authorization, *dryRun); err != nil {
+			fmt.Printf("%s (user %d): %v\n", login, identity.UserID, err)
+			failed++
+		}
+	}
+
+	fmt.Printf("%d identities backfilled, %d failed\n", len(identities)-failed, failed)
+}
+
+func backfill(client *database.Client, identity *models.Identity, login, authorization string, dryRun bool) error {
+	gh, err := providers.FetchGitHubUser(login, authorization)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(gh.Login, login) {
+		return fmt.Errorf("the login now belongs to %s", gh.Login)
+	}
+
+	fmt.Printf("%s (user %d): %d %s\n", login, identity.UserID, gh.GitHubID, gh.NodeID)
+	if dryRun {
+		return nil
+	}
+
+	user, err := client.GetUserByID(identity.UserID)
+	if err != nil {
+		return err
+	}
+	user.GHUser.GitHubID = gh.GitHubID
+	user.GHUser.NodeID = gh.NodeID
+	if err = client.UpdateUser(user); err != nil {
+		return err
+	}
+
+	identity.Subject = providers.GitHubSubject(gh)
+	if err = client.UpdateIdentitySubject(identity); err != nil {
+		return err
+	}
+
+	identity.Profile, err = json.Marshal(user.GHUser)
+	if err != nil {
+		return err
+	}
+	return client.UpdateIdentityProfile(identity)
+}
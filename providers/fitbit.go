@@ -0,0 +1,320 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+const (
+	fitbitAuthorizeURL  = "https://www.fitbit.com/oauth2/authorize"
+	fitbitTokenURL      = "https://api.fitbit.com/oauth2/token"
+	fitbitIntrospectURL = "https://api.fitbit.com/1.1/oauth2/introspect"
+	fitbitUserURL       = "https://api.fitbit.com/1/user/%s/profile.json"
+)
+
+// scopes every FitBit login must grant
+var fitbitScopes = []string{"heartrate", "profile"}
+
+// FitBit : the FitBit application, configured by $FITBIT_ID, $FITBIT_SECRET
+// and $FITBIT_CALLBACK_URL. It uses the authorization code grant with PKCE,
+// so the FitBit token never goes through the browser.
+type FitBit struct {
+	ClientID     string
+	ClientSecret string
+	CallbackURL  string
+}
+
+func init() {
+	register(&FitBit{
+		ClientID:     os.Getenv("FITBIT_ID"),
+		ClientSecret: os.Getenv("FITBIT_SECRET"),
+		CallbackURL:  os.Getenv("FITBIT_CALLBACK_URL"),
+	})
+}
+
+// Name returns the provider identifier
+func (f *FitBit) Name() string {
+	return models.FitBitProvider
+}
+
+// DisplayName returns the name shown on the login pages
+func (f *FitBit) DisplayName() string {
+	return "FitBit"
+}
+
+// AuthorizationURL returns the FitBit login URL
+func (f *FitBit) AuthorizationURL(state string) (string, error) {
+	if f.ClientID == "" || f.CallbackURL == "" {
+		return "", errors.New("$FITBIT_ID and $FITBIT_CALLBACK_URL should be set")
+	}
+
+	verifier, err := codeVerifier(f.Name(), state)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	return fitbitAuthorizeURL + "?" + url.Values{
+		"response_type":         {"code"},
+		"client_id":             {f.ClientID},
+		"redirect_uri":          {f.CallbackURL},
+		"scope":                 {strings.Join(fitbitScopes, " ")},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"state":                 {state},
+	}.Encode(), nil
+}
+
+// Exchange gets an access token for the code FitBit called back with
+func (f *FitBit) Exchange(params map[string]string) (*Token, error) {
+	if f.ClientID == "" || f.ClientSecret == "" || f.CallbackURL == "" {
+		return nil, errors.New("$FITBIT_ID, $FITBIT_SECRET and $FITBIT_CALLBACK_URL should be set")
+	}
+	if params["code"] == "" {
+		return nil, errors.New("code is missing")
+	}
+
+	verifier, err := codeVerifier(f.Name(), params["state"])
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := f.requestToken(url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {f.ClientID},
+		"code":          {params["code"]},
+		"redirect_uri":  {f.CallbackURL},
+		"code_verifier": {verifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = f.verifyToken(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Refresh gets a new access token with the refresh token. FitBit refresh
+// tokens can only be used once, the new one must be kept.
+func (f *FitBit) Refresh(token *Token) (*Token, error) {
+	if f.ClientID == "" || f.ClientSecret == "" {
+		return nil, errors.New("$FITBIT_ID and $FITBIT_SECRET should be set")
+	}
+	if token.RefreshToken == "" {
+		return nil, errors.New("the FitBit token has no refresh token")
+	}
+
+	refreshed, err := f.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	})
+	if e, ok := err.(*fitbitError); ok && e.ErrorType == "invalid_grant" {
+		return nil, ErrRefreshRejected
+	}
+	return refreshed, err
+}
+
+// fitbitError : an error of the FitBit API
+type fitbitError struct {
+	ErrorType string `json:"errorType"`
+	Message   string `json:"message"`
+}
+
+func (e *fitbitError) Error() string {
+	return "FitBit error " + e.ErrorType + ": " + e.Message
+}
+
+// decodeFitBitError returns the first error of a FitBit error response, or
+// nil when it has none
+func decodeFitBitError(body []byte) error {
+	var response struct {
+		Errors []*fitbitError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Errors) == 0 {
+		return nil
+	}
+	return response.Errors[0]
+}
+
+// requestToken calls the FitBit token endpoint, authenticated as our
+// application
+func (f *FitBit) requestToken(form url.Values) (*Token, error) {
+	req, err := http.NewRequest(http.MethodPost, fitbitTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(f.ClientID, f.ClientSecret)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// rejected grants are reported in the body of a 400 or 401 response
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		if body, err := ioutil.ReadAll(resp.Body); err == nil {
+			if e := decodeFitBitError(body); e != nil {
+				return nil, e
+			}
+		}
+	}
+	if err = checkStatusCode(resp, f.DisplayName()); err != nil {
+		return nil, err
+	}
+
+	var token Token
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	return &token, err
+}
+
+// fitbitIntrospection : FitBit token introspection response
+type fitbitIntrospection struct {
+	Active   bool   `json:"active"`
+	Scope    string `json:"scope"`
+	ClientID string `json:"client_id"`
+	UserID   string `json:"user_id"`
+}
+
+// verifyToken checks with FitBit token introspection that the token was
+// issued to our application, for the user it claims, with the scopes we need
+func (f *FitBit) verifyToken(token *Token) error {
+	req, err := http.NewRequest(
+		http.MethodPost,
+		fitbitIntrospectURL,
+		strings.NewReader(url.Values{"token": {token.AccessToken}}.Encode()),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Authorization", "Bearer "+token.AccessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatusCode(resp, f.DisplayName()); err != nil {
+		return err
+	}
+
+	var introspection fitbitIntrospection
+	if err = json.NewDecoder(resp.Body).Decode(&introspection); err != nil {
+		return err
+	}
+	return f.checkIntrospection(&introspection, token)
+}
+
+func (f *FitBit) checkIntrospection(introspection *fitbitIntrospection, token *Token) error {
+	switch {
+	case !introspection.Active:
+		return errors.New("the FitBit token is not active")
+	case introspection.ClientID != f.ClientID:
+		return fmt.Errorf("the FitBit token was issued to another application (%s)", introspection.ClientID)
+	case token.UserID != "" && introspection.UserID != token.UserID:
+		return errors.New("the FitBit token was issued for another user")
+	}
+
+	// FitBit formats the scope like {HEARTRATE=READ, PROFILE=READ}
+	scope := strings.ToLower(introspection.Scope)
+	for _, required := range fitbitScopes {
+		if !strings.Contains(scope, required) {
+			return fmt.Errorf("the %s scope is required - scope: %s", required, introspection.Scope)
+		}
+	}
+
+	// the profile is fetched for the user the token was issued for
+	token.UserID = introspection.UserID
+	return nil
+}
+
+// FetchProfile gets the profile of the FitBit user
+func (f *FitBit) FetchProfile(token *Token) (Profile, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(fitbitUserURL, token.UserID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", token.TokenType, token.AccessToken))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatusCode(resp, f.DisplayName()); err != nil {
+		return nil, err
+	}
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := decodeFitBitProfile(raw)
+	if err != nil {
+		return nil, err
+	}
+	if profile.EncodedID != token.UserID {
+		return nil, errors.New("FitBit returned the profile of another user")
+	}
+	return profile, nil
+}
+
+// decodeFitBitProfile reads a profile.json response, that wraps the profile
+// in a user object, and keeps the whole response
+func decodeFitBitProfile(raw []byte) (*fitbitProfile, error) {
+	var envelope struct {
+		User *models.FitBitProfile `json:"user"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.User == nil || envelope.User.EncodedID == "" {
+		return nil, errors.New("the FitBit response has no user profile")
+	}
+
+	return &fitbitProfile{FitBitProfile: envelope.User, raw: string(raw)}, nil
+}
+
+// Forget removes the FitBit profile from the user
+func (f *FitBit) Forget(user *models.User) {
+	user.FitBitUser = nil
+}
+
+type fitbitProfile struct {
+	*models.FitBitProfile
+
+	// the profile.json response, stored as is
+	raw string
+}
+
+func (p *fitbitProfile) Identity() *Identity {
+	return &Identity{
+		Provider: models.FitBitProvider,
+		Subject:  p.EncodedID,
+		Login:    p.DisplayName,
+		Name:     p.FullName,
+		Picture:  p.FitBitUser().Avatar,
+	}
+}
+
+func (p *fitbitProfile) Apply(user *models.User) {
+	user.FitBitUser = p.FitBitUser()
+	user.FitBitUser.RawPayload = p.raw
+}
@@ -0,0 +1,274 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/socialement-competents/goauth/models"
+)
+
+const (
+	githubAuthorizeURL   = "https://github.com/login/oauth/authorize"
+	githubAccessTokenURL = "https://github.com/login/oauth/access_token"
+	githubUserURL        = "https://api.github.com/user"
+	githubUsersURL       = "https://api.github.com/users/"
+	githubEmailsURL      = "https://api.github.com/user/emails"
+
+	// the public email of /user can be empty or unverified, user:email
+	// gives access to every address and whether GitHub verified it
+	githubScope = "user:email"
+
+	// GitHubLegacyPrefix : prefix of the subject of the GitHub identities
+	// keyed by login, until the backfill resolves their numeric ID
+	GitHubLegacyPrefix = "login:"
+)
+
+// GitHub : the GitHub OAuth app, configured by $GH_ID and $GH_SECRET
+type GitHub struct {
+	ClientID     string
+	ClientSecret string
+}
+
+func init() {
+	register(&GitHub{
+		ClientID:     os.Getenv("GH_ID"),
+		ClientSecret: os.Getenv("GH_SECRET"),
+	})
+}
+
+// Name returns the provider identifier
+func (g *GitHub) Name() string {
+	return models.GithubProvider
+}
+
+// DisplayName returns the name shown on the login pages
+func (g *GitHub) DisplayName() string {
+	return "GitHub"
+}
+
+// AuthorizationURL returns the GitHub login URL. GitHub calls back the
+// callback URL set in the OAuth app.
+func (g *GitHub) AuthorizationURL(state string) (string, error) {
+	if g.ClientID == "" {
+		return "", errors.New("$GH_ID should be set")
+	}
+
+	return githubAuthorizeURL + "?" + url.Values{
+		"client_id": {g.ClientID},
+		"scope":     {githubScope},
+		"state":     {state},
+	}.Encode(), nil
+}
+
+// Exchange gets an access token for the code GitHub called back with
+func (g *GitHub) Exchange(params map[string]string) (*Token, error) {
+	if g.ClientID == "" || g.ClientSecret == "" {
+		return nil, errors.New("$GH_ID and $GH_SECRET should be set")
+	}
+	if params["code"] == "" {
+		return nil, errors.New("code is missing")
+	}
+
+	return g.requestToken(url.Values{"code": {params["code"]}})
+}
+
+// Refresh gets a new access token with the refresh token. Only the tokens
+// of the GitHub apps that enabled token expiration can be refreshed, OAuth
+// app tokens don't expire.
+func (g *GitHub) Refresh(token *Token) (*Token, error) {
+	if g.ClientID == "" || g.ClientSecret == "" {
+		return nil, errors.New("$GH_ID and $GH_SECRET should be set")
+	}
+	if token.RefreshToken == "" {
+		return nil, errors.New("the GitHub token can't be refreshed")
+	}
+
+	return g.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	})
+}
+
+// requestToken calls the GitHub access token endpoint, authenticated as our
+// OAuth app
+func (g *GitHub) requestToken(query url.Values) (*Token, error) {
+	query.Set("client_id", g.ClientID)
+	query.Set("client_secret", g.ClientSecret)
+
+	req, err := http.NewRequest(http.MethodPost, githubAccessTokenURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatusCode(resp, g.DisplayName()); err != nil {
+		return nil, err
+	}
+
+	// GitHub reports errors with a 200 response
+	var token struct {
+		Token
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	// the refresh token expired or was already used
+	if token.Error == "bad_refresh_token" {
+		return nil, ErrRefreshRejected
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("%s: %s", token.Error, token.ErrorDescription)
+	}
+	return &token.Token, nil
+}
+
+// FetchProfile gets the authenticated GitHub user, with his primary email
+// when GitHub verified it. Tokens granted without the user:email scope only
+// give the public email, which isn't trusted as verified.
+func (g *GitHub) FetchProfile(token *Token) (Profile, error) {
+	authorization := "token " + token.AccessToken
+
+	user, err := getGitHubUser(githubUserURL, authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []githubEmail
+	err = getGitHub(githubEmailsURL, authorization, &emails)
+	if err != nil && !emailsUnavailable(err) {
+		return nil, err
+	}
+	if email, ok := primaryVerifiedEmail(emails); ok {
+		user.Email = email
+		user.EmailVerified = true
+	}
+
+	return &githubProfile{user}, nil
+}
+
+// FetchGitHubUser gets the public profile of a GitHub login. The
+// authorization, if any, raises the rate limit.
+func FetchGitHubUser(login, authorization string) (*models.GHUser, error) {
+	return getGitHubUser(githubUsersURL+url.PathEscape(login), authorization)
+}
+
+func getGitHubUser(userURL, authorization string) (*models.GHUser, error) {
+	var user models.GHUser
+	if err := getGitHub(userURL, authorization, &user); err != nil {
+		return nil, err
+	}
+	if user.GitHubID == 0 {
+		return nil, errors.New("GitHub didn't give the user ID")
+	}
+
+	// only the emails GitHub says it verified are trusted
+	user.EmailVerified = false
+	return &user, nil
+}
+
+// githubEmail : an address of the user, from /user/emails
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// emailsUnavailable returns true if GitHub refused to list the emails of the
+// user, as the token wasn't granted the user:email scope
+func emailsUnavailable(err error) bool {
+	e, ok := err.(*githubStatusError)
+	return ok && (e.StatusCode == http.StatusForbidden || e.StatusCode == http.StatusNotFound)
+}
+
+// primaryVerifiedEmail returns the primary address of the user, if GitHub
+// verified it
+func primaryVerifiedEmail(emails []githubEmail) (string, bool) {
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, true
+		}
+	}
+	return "", false
+}
+
+// getGitHub decodes the response of a GitHub API endpoint into v
+func getGitHub(apiURL, authorization string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/vnd.github.v3+json")
+	if authorization != "" {
+		req.Header.Add("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return &githubStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if err = checkStatusCode(resp, "GitHub"); err != nil {
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// githubStatusError : an error response of the GitHub API
+type githubStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *githubStatusError) Error() string {
+	return "bad GitHub response: " + e.Status
+}
+
+// GitHubSubject returns the identity subject of a GitHub account: its
+// numeric ID, as the login can change
+func GitHubSubject(user *models.GHUser) string {
+	return strconv.FormatInt(user.GitHubID, 10)
+}
+
+// Forget removes the GitHub profile from the user
+func (g *GitHub) Forget(user *models.User) {
+	user.GHUser = nil
+}
+
+type githubProfile struct {
+	*models.GHUser
+}
+
+func (p *githubProfile) Identity() *Identity {
+	return &Identity{
+		Provider: models.GithubProvider,
+		Subject:  GitHubSubject(p.GHUser),
+		Login:    p.Login,
+		Name:     p.Name,
+		Email:    p.Email,
+		Picture:  p.Image,
+
+		EmailVerified: p.EmailVerified,
+	}
+}
+
+func (p *githubProfile) Apply(user *models.User) {
+	user.GHUser = p.GHUser
+}
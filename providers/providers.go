@@ -0,0 +1,393 @@
+package providers
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// Provider : an upstream identity provider users can log in with
+type Provider interface {
+	// Name returns the provider identifier, as used in the provider parameter
+	Name() string
+
+	// DisplayName returns the name shown on the login pages
+	DisplayName() string
+
+	// AuthorizationURL returns the URL sending the user to the provider
+	// login. The state is given back to the callback.
+	AuthorizationURL(state string) (string, error)
+
+	// Exchange returns the upstream access token from the parameters the
+	// provider called back with
+	Exchange(params map[string]string) (*Token, error)
+
+	// FetchProfile returns the user the token was issued for
+	FetchProfile(token *Token) (Profile, error)
+
+	// Refresh returns a new token for the refresh token of an expiring one.
+	// It returns ErrRefreshRejected when the provider rejected the refresh
+	// token (invalid_grant), which will never be accepted again.
+	Refresh(token *Token) (*Token, error)
+
+	// Forget removes the provider specific fields from the GOAuth user, once
+	// the provider account is unlinked
+	Forget(user *models.User)
+}
+
+// Profile : a user as described by a provider
+type Profile interface {
+	// Identity returns the normalized user
+	Identity() *Identity
+
+	// Apply stores the provider specific fields on the GOAuth user
+	Apply(user *models.User)
+}
+
+// Token : an access token issued by a provider
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+
+	// Validity in seconds, 0 when unknown
+	ExpiresIn int `json:"expires_in"`
+
+	// Upstream user ID, when the provider gives it along with the token
+	UserID string `json:"user_id"`
+
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Identity : a user as known by any provider
+type Identity struct {
+	Provider string `json:"provider"`
+
+	// Subject : the provider's unique identifier of the user
+	Subject string `json:"sub"`
+
+	Login   string `json:"login"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Picture string `json:"picture"`
+
+	// EmailVerified : the provider verified the user owns the email
+	EmailVerified bool `json:"email_verified"`
+}
+
+// Providers : every provider users can log in with, by name
+var Providers = map[string]Provider{}
+
+func register(p Provider) {
+	Providers[p.Name()] = p
+}
+
+// Get returns a provider from its name
+func Get(name string) (Provider, error) {
+	p, ok := Providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %s", name)
+	}
+	return p, nil
+}
+
+// LoginURL returns the URL sending the user to the login of a provider. The
+// GOAuth request ID is used as the upstream state, so the callback can find
+// which request it completes.
+func LoginURL(name, state string) (string, error) {
+	p, err := Get(name)
+	if err != nil {
+		return "", err
+	}
+	return p.AuthorizationURL(state)
+}
+
+// DisplayName returns the name of a provider shown on the login pages
+func DisplayName(name string) string {
+	p, err := Get(name)
+	if err != nil {
+		return name
+	}
+	return p.DisplayName()
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+var (
+	// ErrLinkedToAnotherUser : the provider account already is an identity of
+	// another user
+	ErrLinkedToAnotherUser = errors.New("this account is already linked to another user")
+
+	// ErrProviderAlreadyLinked : the user already linked another account of
+	// the provider, he has to unlink it first
+	ErrProviderAlreadyLinked = errors.New("another account of this provider is already linked")
+
+	// ErrLastIdentity : a user must keep an identity to log in with
+	ErrLastIdentity = errors.New("the last identity of a user can't be unlinked")
+
+	// ErrRefreshRejected : the provider won't refresh the token anymore, the
+	// user has to log in again
+	ErrRefreshRejected = errors.New("the provider rejected the refresh token")
+)
+
+// Login handles the callback of a provider: it gets the user's profile with
+// the callback parameters, and finds the user the provider account is linked
+// to, or creates one. The provider token is kept for our applications. It
+// returns true when the user was created.
+func Login(db *database.Client, p Provider, params map[string]string) (*models.User, bool, error) {
+	profile, identity, token, err := fetchIdentity(p, params)
+	if err != nil {
+		return nil, false, err
+	}
+
+	user, created, err := loginIdentity(db, p, profile, identity)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err = StoreToken(db, user.ID, p, token); err != nil {
+		return nil, false, fmt.Errorf("storing the %s token failed: %v", p.DisplayName(), err)
+	}
+	return user, created, nil
+}
+
+func loginIdentity(db *database.Client, p Provider, profile Profile, identity *models.Identity) (*models.User, bool, error) {
+	existing, err := db.GetIdentity(identity.Provider, identity.Subject)
+	if err == sql.ErrNoRows {
+		existing, err = upgradeLegacyIdentity(db, profile, identity)
+	}
+	if err == sql.ErrNoRows {
+		user := &models.User{Provider: p.Name(), LastLogin: time.Now()}
+		profile.Apply(user)
+		if _, err = db.CreateUser(user); err != nil {
+			return nil, false, fmt.Errorf("creating the user failed: %v", err)
+		}
+
+		identity.UserID = user.ID
+		if err = db.CreateIdentity(identity); err != nil {
+			return nil, false, fmt.Errorf("creating the identity failed: %v", err)
+		}
+		return user, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	user, err := refreshIdentity(db, existing, profile, identity.Profile)
+	return user, false, err
+}
+
+// Link handles the callback of a provider when a logged in user links
+// another account: the provider account becomes an identity of the user
+func Link(db *database.Client, p Provider, params map[string]string, userID int) (*models.User, error) {
+	profile, identity, token, err := fetchIdentity(p, params)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := linkIdentity(db, profile, identity, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = StoreToken(db, user.ID, p, token); err != nil {
+		return nil, fmt.Errorf("storing the %s token failed: %v", p.DisplayName(), err)
+	}
+	return user, nil
+}
+
+func linkIdentity(db *database.Client, profile Profile, identity *models.Identity, userID int) (*models.User, error) {
+	existing, err := db.GetIdentity(identity.Provider, identity.Subject)
+	if err == sql.ErrNoRows {
+		existing, err = upgradeLegacyIdentity(db, profile, identity)
+	}
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, ErrLinkedToAnotherUser
+		}
+		// linked already, the profile is refreshed like on login
+		return refreshIdentity(db, existing, profile, identity.Profile)
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	identities, err := db.ListIdentities(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range identities {
+		if i.Provider == identity.Provider {
+			return nil, ErrProviderAlreadyLinked
+		}
+	}
+
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Apply(user)
+	if err = db.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("updating the user failed: %v", err)
+	}
+
+	identity.UserID = userID
+	if err = db.CreateIdentity(identity); err != nil {
+		return nil, fmt.Errorf("creating the identity failed: %v", err)
+	}
+	return user, nil
+}
+
+// Unlink removes the identity of a provider from a user, who must keep
+// another one to log in with, and forgets the provider token. It returns
+// sql.ErrNoRows when the provider wasn't linked.
+func Unlink(db *database.Client, userID int, name string) error {
+	p, err := Get(name)
+	if err != nil {
+		return err
+	}
+
+	identities, err := db.ListIdentities(userID)
+	if err != nil {
+		return err
+	}
+
+	linked := false
+	for _, i := range identities {
+		linked = linked || i.Provider == p.Name()
+	}
+	if !linked {
+		return sql.ErrNoRows
+	}
+	if len(identities) == 1 {
+		return ErrLastIdentity
+	}
+
+	if err = db.DeleteIdentity(userID, p.Name()); err != nil {
+		return err
+	}
+	if err = forgetToken(db, userID, p.Name()); err != nil {
+		return err
+	}
+
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	p.Forget(user)
+	return db.UpdateUser(user)
+}
+
+// fetchIdentity gets the token and the profile of the user who logged in
+// with a provider
+func fetchIdentity(p Provider, params map[string]string) (Profile, *models.Identity, *Token, error) {
+	token, err := p.Exchange(params)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting the access token from %s: %v", p.DisplayName(), err)
+	}
+
+	profile, err := p.FetchProfile(token)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting the user from %s: %v", p.DisplayName(), err)
+	}
+
+	subject := profile.Identity().Subject
+	if subject == "" {
+		return nil, nil, nil, fmt.Errorf("%s didn't identify the user", p.DisplayName())
+	}
+
+	raw, err := json.Marshal(profile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return profile, &models.Identity{Provider: p.Name(), Subject: subject, Profile: raw}, token, nil
+}
+
+// upgradeLegacyIdentity returns the GitHub identity still keyed by the login
+// of the account (migration 20), with its subject upgraded to the numeric ID.
+// It returns sql.ErrNoRows when there is none, or when the login belonged to
+// another GitHub account.
+func upgradeLegacyIdentity(db *database.Client, profile Profile, identity *models.Identity) (*models.Identity, error) {
+	login := profile.Identity().Login
+	if identity.Provider != models.GithubProvider || login == "" {
+		return nil, sql.ErrNoRows
+	}
+
+	legacy, err := db.GetIdentity(models.GithubProvider, GitHubLegacyPrefix+login)
+	if err != nil {
+		return nil, err
+	}
+
+	// the backfill may have resolved the ID of the user already
+	user, err := db.GetUserByID(legacy.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.GHUser != nil && user.GHUser.GitHubID != 0 && GitHubSubject(user.GHUser) != identity.Subject {
+		return nil, sql.ErrNoRows
+	}
+
+	legacy.Subject = identity.Subject
+	if err = db.UpdateIdentitySubject(legacy); err != nil {
+		return nil, fmt.Errorf("upgrading the identity failed: %v", err)
+	}
+	return legacy, nil
+}
+
+// refreshIdentity updates the user an identity belongs to with the newly
+// fetched profile (we don't query the provider every time, because of rate
+// limits)
+func refreshIdentity(db *database.Client, identity *models.Identity, profile Profile, raw []byte) (*models.User, error) {
+	user, err := db.GetUserByID(identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Apply(user)
+	user.LastLogin = time.Now()
+	if err = db.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("updating the user failed: %v", err)
+	}
+
+	identity.Profile = raw
+	if err = db.UpdateIdentityProfile(identity); err != nil {
+		return nil, fmt.Errorf("updating the identity failed: %v", err)
+	}
+	return user, nil
+}
+
+// codeVerifier returns the PKCE code verifier (RFC 7636) of the login with a
+// provider. It is derived from the state with the master key, so it doesn't
+// have to be stored until the callback, and can't be guessed from the state.
+func codeVerifier(provider, state string) (string, error) {
+	if state == "" {
+		return "", errors.New("the state is missing: the login must be started by GOAuth")
+	}
+
+	mac, err := keys.MAC([]byte("code_verifier " + provider + " " + state))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(mac), nil
+}
+
+func checkStatusCode(resp *http.Response, provider string) error {
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("bad %s response: %s", provider, resp.Status)
+	} else if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected return code: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/models"
+)
+
+func TestEveryProviderIsRegistered(t *testing.T) {
+	for _, name := range models.Providers {
+		p, err := Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Name() != name {
+			t.Errorf("provider %s is registered as %s", p.Name(), name)
+		}
+	}
+
+	if _, err := Get("myspace"); err == nil {
+		t.Error("unknown providers should be rejected")
+	}
+}
+
+func setMasterKey(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	os.Setenv("MASTER_KEY", base64.StdEncoding.EncodeToString(key))
+}
+
+func TestAuthorizationURLCarriesState(t *testing.T) {
+	setMasterKey(t)
+
+	providers := []Provider{
+		&GitHub{ClientID: "gh"},
+		&FitBit{ClientID: "fb", CallbackURL: "https://auth.example.com/callback/fitbit"},
+	}
+	for _, p := range providers {
+		loginURL, err := p.AuthorizationURL("request-id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(loginURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Query().Get("state") != "request-id" {
+			t.Errorf("%s: the state is missing from %s", p.Name(), loginURL)
+		}
+	}
+
+	if _, err := (&GitHub{}).AuthorizationURL("request-id"); err == nil {
+		t.Error("an unconfigured provider should fail")
+	}
+}
+
+func TestNormalizedIdentity(t *testing.T) {
+	gh := &githubProfile{&models.GHUser{GitHubID: 583231, Login: "potato", Name: "Miguel", Image: "https://avatars/1"}}
+	if id := gh.Identity(); id.Subject != "583231" || id.Login != "potato" || id.Name != "Miguel" || id.Picture != "https://avatars/1" {
+		t.Errorf("unexpected GitHub identity %+v", id)
+	}
+
+	fb := &fitbitProfile{FitBitProfile: &models.FitBitProfile{EncodedID: "22D4DN", FullName: "Miguel"}}
+	if id := fb.Identity(); id.Subject != "22D4DN" || id.Name != "Miguel" {
+		t.Errorf("unexpected FitBit identity %+v", id)
+	}
+
+	user := &models.User{}
+	fb.Apply(user)
+	if user.FitBitUser.EncodedID != "22D4DN" {
+		t.Error("the FitBit profile should be stored on the user")
+	}
+}
+
+func TestForgettingProfile(t *testing.T) {
+	user := &models.User{
+		GHUser:     &models.GHUser{Login: "potato"},
+		FitBitUser: &models.FitBitUser{EncodedID: "22D4DN"},
+	}
+
+	(&FitBit{}).Forget(user)
+	if user.FitBitUser != nil || user.GHUser.Login != "potato" {
+		t.Errorf("only the FitBit profile should be removed: %+v %+v", user.GHUser, user.FitBitUser)
+	}
+
+	(&GitHub{}).Forget(user)
+	user.RemoveNils()
+	if user.Login != "" {
+		t.Error("the GitHub profile should be removed")
+	}
+}
+
+func TestGitHubUserIsIdentifiedByID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token gho_123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"login":"octocat","id":583231,"node_id":"MDQ6VXNlcjU4MzIzMQ==","name":"The Octocat"}`))
+	}))
+	defer server.Close()
+
+	user, err := getGitHubUser(server.URL, "token gho_123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.GitHubID != 583231 || user.NodeID != "MDQ6VXNlcjU4MzIzMQ==" || user.Login != "octocat" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	if _, err = getGitHubUser(server.URL, ""); err == nil {
+		t.Error("a rejected token should fail")
+	}
+}
+
+func TestPrimaryVerifiedEmail(t *testing.T) {
+	emails := []githubEmail{
+		{Email: "old@example.com", Verified: true},
+		{Email: "miguel@example.com", Primary: true, Verified: true},
+	}
+	if email, ok := primaryVerifiedEmail(emails); !ok || email != "miguel@example.com" {
+		t.Errorf("expected the primary address, got %s", email)
+	}
+
+	emails[1].Verified = false
+	if email, ok := primaryVerifiedEmail(emails); ok {
+		t.Errorf("an unverified primary address should not be used, got %s", email)
+	}
+}
+
+func TestEmailsUnavailableWithoutScope(t *testing.T) {
+	if !emailsUnavailable(&githubStatusError{StatusCode: http.StatusForbidden}) {
+		t.Error("a token without the user:email scope should fall back to the public email")
+	}
+	if emailsUnavailable(&githubStatusError{StatusCode: http.StatusInternalServerError}) {
+		t.Error("a GitHub failure should fail the login")
+	}
+	if emailsUnavailable(errors.New("connection reset")) {
+		t.Error("a network failure should fail the login")
+	}
+}
+
+func TestFitBitUsesPKCE(t *testing.T) {
+	setMasterKey(t)
+
+	f := &FitBit{ClientID: "fb", CallbackURL: "https://auth.example.com/callback/fitbit"}
+	loginURL, err := f.AuthorizationURL("request-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(loginURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("response_type") != "code" || u.Query().Get("code_challenge_method") != "S256" {
+		t.Errorf("FitBit should use the authorization code grant with PKCE: %s", loginURL)
+	}
+
+	verifier, err := codeVerifier(f.Name(), "request-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	if u.Query().Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+		t.Error("the challenge should be derived from the verifier of the state")
+	}
+
+	other, _ := codeVerifier(f.Name(), "other-request")
+	if other == verifier {
+		t.Error("each login should have its own verifier")
+	}
+	if _, err = codeVerifier(f.Name(), ""); err == nil {
+		t.Error("a login without state should be rejected")
+	}
+}
+
+func TestFitBitTokenMustBeOurs(t *testing.T) {
+	f := &FitBit{ClientID: "22D4DN"}
+	valid := fitbitIntrospection{Active: true, ClientID: "22D4DN", UserID: "ABC123", Scope: "{HEARTRATE=READ, PROFILE=READ}"}
+
+	token := &Token{AccessToken: "token"}
+	if err := f.checkIntrospection(&valid, token); err != nil || token.UserID != "ABC123" {
+		t.Errorf("the token should be accepted for ABC123: %v", err)
+	}
+
+	invalid := map[string]fitbitIntrospection{
+		"inactive":      {ClientID: "22D4DN", UserID: "ABC123", Scope: valid.Scope},
+		"other app":     {Active: true, ClientID: "OTHER", UserID: "ABC123", Scope: valid.Scope},
+		"missing scope": {Active: true, ClientID: "22D4DN", UserID: "ABC123", Scope: "{PROFILE=READ}"},
+		"other user":    {Active: true, ClientID: "22D4DN", UserID: "XYZ789", Scope: valid.Scope},
+	}
+	for name, introspection := range invalid {
+		introspection := introspection
+		if err := f.checkIntrospection(&introspection, &Token{UserID: "ABC123"}); err == nil {
+			t.Errorf("%s: the token should be rejected", name)
+		}
+	}
+}
+
+func TestDecodingFitBitProfile(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/fitbit_profile.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := decodeFitBitProfile(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := profile.FitBitProfile
+	if p.EncodedID != "22D4DN" || p.FullName != "Miguel Rodriguez" || p.DisplayName != "Miguel R." || p.Age != 31 {
+		t.Errorf("unexpected identity fields %+v", p)
+	}
+	if p.Gender != "MALE" || p.Timezone != "Europe/Paris" || p.Locale != "fr_FR" || p.MemberSince != "2016-09-02" {
+		t.Errorf("unexpected personal fields %+v", p)
+	}
+	if p.Height != 178 || p.HeightUnit != "METRIC" || p.Weight != 74.2 || p.WeightUnit != "METRIC" || p.OffsetFromUTCMillis != 7200000 {
+		t.Errorf("unexpected measurement fields %+v", p)
+	}
+
+	user := &models.User{}
+	profile.Apply(user)
+	if user.EncodedID != "22D4DN" || user.FitBitUser.Avatar != p.Avatar150 || user.RawPayload != string(raw) {
+		t.Errorf("unexpected user %+v", user.FitBitUser)
+	}
+
+	// the identity keeps the whole profile, without the raw response
+	stored, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded models.FitBitProfile
+	if err = json.Unmarshal(stored, &decoded); err != nil || decoded != *p {
+		t.Errorf("the stored profile should round trip: %v", err)
+	}
+}
+
+func TestDecodingFitBitProfileWithoutEnvelope(t *testing.T) {
+	if _, err := decodeFitBitProfile([]byte(`{"encodedId":"22D4DN","fullName":"Miguel"}`)); err == nil {
+		t.Error("a profile outside of the user object should be rejected")
+	}
+	if _, err := decodeFitBitProfile([]byte(`<html>`)); err == nil {
+		t.Error("a response that isn't JSON should be rejected")
+	}
+}
+
+func TestDecodingFitBitError(t *testing.T) {
+	body := []byte(`{"errors":[{"errorType":"invalid_grant","message":"Refresh token invalid"}],"success":false}`)
+	e, ok := decodeFitBitError(body).(*fitbitError)
+	if !ok || e.ErrorType != "invalid_grant" {
+		t.Errorf("unexpected error %v", e)
+	}
+
+	if err := decodeFitBitError([]byte(`{"success":false}`)); err != nil {
+		t.Errorf("a response without errors shouldn't be an error, got %v", err)
+	}
+}
+
+func TestOpeningStoredToken(t *testing.T) {
+	setMasterKey(t)
+
+	accessToken, err := keys.Seal([]byte("access"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshToken, err := keys.Seal([]byte("refresh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := &models.UpstreamToken{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	token, err := openToken(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "access" || token.RefreshToken != "refresh" || token.ExpiresIn <= 3500 {
+		t.Errorf("unexpected token %+v", token)
+	}
+
+	if stored.ExpiresWithin(RefreshMargin) || !stored.ExpiresWithin(2*time.Hour) {
+		t.Error("the token should only be refreshed close to its expiry")
+	}
+	if (&models.UpstreamToken{}).ExpiresWithin(RefreshMargin) {
+		t.Error("tokens without expiry should never be refreshed")
+	}
+
+	stored.AccessToken = "not sealed"
+	if _, err = openToken(stored); err == nil {
+		t.Error("a token that wasn't sealed should be rejected")
+	}
+}
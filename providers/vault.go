@@ -0,0 +1,174 @@
+package providers
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/socialement-competents/goauth/database"
+	"github.com/socialement-competents/goauth/keys"
+	"github.com/socialement-competents/goauth/models"
+)
+
+// RefreshMargin : an upstream token expiring sooner is refreshed before being
+// handed out
+const RefreshMargin = 5 * time.Minute
+
+// StoreToken keeps the token a provider issued for a user, encrypted with the
+// master key
+func StoreToken(db *database.Client, userID int, p Provider, token *Token) error {
+	stored, err := sealToken(userID, p, token)
+	if err != nil {
+		return err
+	}
+	return db.SaveUpstreamToken(stored)
+}
+
+// sealToken encrypts a token to store it
+func sealToken(userID int, p Provider, token *Token) (*models.UpstreamToken, error) {
+	accessToken, err := keys.Seal([]byte(token.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken := ""
+	if token.RefreshToken != "" {
+		if refreshToken, err = keys.Seal([]byte(token.RefreshToken)); err != nil {
+			return nil, err
+		}
+	}
+
+	stored := &models.UpstreamToken{
+		UserID:       userID,
+		Provider:     p.Name(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    token.TokenType,
+		Scope:        token.Scope,
+	}
+	if token.ExpiresIn > 0 {
+		stored.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return stored, nil
+}
+
+// FreshToken returns the token a provider issued for a user, refreshed first
+// when it is about to expire. It returns sql.ErrNoRows when the user never
+// logged in with the provider since tokens are kept, and ErrRefreshRejected
+// when the token couldn't be refreshed anymore.
+func FreshToken(db *database.Client, userID int, name string) (*Token, error) {
+	p, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := db.GetUpstreamToken(userID, p.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.ExpiresWithin(RefreshMargin) {
+		return refresh(db, p, userID, RefreshMargin)
+	}
+	return openToken(stored)
+}
+
+// RefreshExpiringTokens refreshes the upstream tokens expiring in less than
+// the given duration, and returns how many failed
+func RefreshExpiringTokens(db *database.Client, within time.Duration) (int, error) {
+	tokens, err := db.ListExpiringUpstreamTokens(time.Now().Add(within))
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for _, stored := range tokens {
+		p, err := Get(stored.Provider)
+		if err == nil {
+			_, err = refresh(db, p, stored.UserID, within)
+		}
+		if err != nil {
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// refresh gets a new token from the provider and stores it, unless another
+// refresh did while the token was locked. Providers that don't rotate refresh
+// tokens keep the previous one. A token whose refresh token was rejected is
+// deleted, so it isn't refreshed again until the user logs in.
+func refresh(db *database.Client, p Provider, userID int, within time.Duration) (*Token, error) {
+	var token *Token
+	var rejected *models.UpstreamToken
+
+	_, err := db.RefreshUpstreamToken(userID, p.Name(), func(stored *models.UpstreamToken) (*models.UpstreamToken, error) {
+		current, err := openToken(stored)
+		if err != nil {
+			return nil, err
+		}
+		if !stored.ExpiresWithin(within) {
+			token = current
+			return nil, nil
+		}
+
+		refreshed, err := p.Refresh(current)
+		if err == ErrRefreshRejected {
+			rejected = stored
+		}
+		if err != nil {
+			return nil, err
+		}
+		if refreshed.RefreshToken == "" {
+			refreshed.RefreshToken = current.RefreshToken
+		}
+
+		token = refreshed
+		return sealToken(userID, p, refreshed)
+	})
+
+	if rejected != nil {
+		if deleteErr := db.DeleteRejectedUpstreamToken(rejected); deleteErr != nil {
+			return nil, deleteErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// openToken decrypts a stored token. ExpiresIn is the remaining validity.
+func openToken(stored *models.UpstreamToken) (*Token, error) {
+	accessToken, err := keys.Open(stored.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{
+		AccessToken: string(accessToken),
+		TokenType:   stored.TokenType,
+		Scope:       stored.Scope,
+	}
+
+	if stored.RefreshToken != "" {
+		refreshToken, err := keys.Open(stored.RefreshToken)
+		if err != nil {
+			return nil, err
+		}
+		token.RefreshToken = string(refreshToken)
+	}
+
+	if !stored.ExpiresAt.IsZero() {
+		token.ExpiresIn = int(time.Until(stored.ExpiresAt).Seconds())
+	}
+	return token, nil
+}
+
+// forgetToken removes the tokens of a provider the user unlinked
+func forgetToken(db *database.Client, userID int, name string) error {
+	err := db.DeleteUpstreamToken(userID, name)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}